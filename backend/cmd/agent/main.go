@@ -0,0 +1,294 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// kk-nav 远程检测代理：部署在隔离网络中，定期从服务端拉取所在区域的链接，
+// 在本地检测后上报结果。
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"kk-nav/internal/config"
+	"kk-nav/internal/httpclient"
+	"kk-nav/internal/services"
+
+	"go.uber.org/zap"
+)
+
+// version 代理版本，心跳时上报
+const version = "1.0.0"
+
+// reportBatchSize 单次上报的结果数
+const reportBatchSize = 500
+
+// schedule 服务端下发的检测调度参数
+type schedule struct {
+	Zone            string `json:"zone"`
+	Enabled         bool   `json:"enabled"`
+	IntervalSeconds int64  `json:"interval_seconds"`
+}
+
+func (s schedule) interval() time.Duration {
+	if s.IntervalSeconds <= 0 {
+		return time.Hour
+	}
+	return time.Duration(s.IntervalSeconds) * time.Second
+}
+
+// client 服务端代理接口客户端
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// call 调用代理接口，解析统一响应结构中的 data
+func (c *client) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1/agent"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&envelope); err != nil {
+		return fmt.Errorf("%s %s: HTTP %d: %w", method, path, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || envelope.Code != 0 {
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, envelope.Message)
+	}
+	if out != nil {
+		return json.Unmarshal(envelope.Data, out)
+	}
+	return nil
+}
+
+// heartbeat 发送心跳并返回最新的调度参数
+func (c *client) heartbeat(ctx context.Context) (schedule, error) {
+	hostname, _ := os.Hostname()
+	var s schedule
+	err := c.call(ctx, http.MethodPost, "/heartbeat", map[string]string{
+		"version":  version,
+		"hostname": hostname,
+	}, &s)
+	return s, err
+}
+
+// agent 检测代理
+type agent struct {
+	client *client
+	prober *services.Prober
+	cfg    config.CheckerConfig
+	logger *zap.Logger
+}
+
+// check 拉取所在区域的链接，检测后分批上报结果
+func (a *agent) check(ctx context.Context) (schedule, error) {
+	var resp struct {
+		schedule
+		Links []services.AgentLink `json:"links"`
+	}
+	if err := a.client.call(ctx, http.MethodGet, "/links", nil, &resp); err != nil {
+		return schedule{}, err
+	}
+	a.logger.Info("Checking links", zap.String("zone", resp.Zone), zap.Int("count", len(resp.Links)))
+
+	start := time.Now()
+	var mu sync.Mutex
+	results := make([]services.AgentResult, 0, len(resp.Links))
+	services.ProbeAgentLinks(ctx, a.prober, a.cfg, resp.Links, func(result services.AgentResult) {
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	})
+	if ctx.Err() != nil {
+		return resp.schedule, ctx.Err()
+	}
+
+	accepted := 0
+	for i := 0; i < len(results); i += reportBatchSize {
+		end := i + reportBatchSize
+		if end > len(results) {
+			end = len(results)
+		}
+		var report struct {
+			Accepted int `json:"accepted"`
+		}
+		if err := a.client.call(ctx, http.MethodPost, "/results", map[string]interface{}{"results": results[i:end]}, &report); err != nil {
+			return resp.schedule, fmt.Errorf("report results: %w", err)
+		}
+		accepted += report.Accepted
+	}
+
+	a.logger.Info("Link check completed",
+		zap.Int("checked", len(results)),
+		zap.Int("accepted", accepted),
+		zap.Duration("duration", time.Since(start)))
+	return resp.schedule, nil
+}
+
+// run 心跳和定时检测循环
+func (a *agent) run(ctx context.Context, heartbeatInterval time.Duration) {
+	current, err := a.client.heartbeat(ctx)
+	if err != nil {
+		a.logger.Error("Heartbeat failed", zap.Error(err))
+	} else {
+		a.logger.Info("Connected to server",
+			zap.String("zone", current.Zone),
+			zap.Bool("enabled", current.Enabled),
+			zap.Duration("interval", current.interval()))
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// 服务端开启检测时启动后立即检测一次，之后按服务端设置的间隔检测
+	var lastCheck time.Time
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	// reset 按当前设置重新安排下一次检测；检测被关闭或暂停（包括心跳失败、尚未取得设置）时停止定时器，
+	// 由心跳在重新开启后恢复
+	reset := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if !current.Enabled {
+			return
+		}
+		wait := time.Until(lastCheck.Add(current.interval()))
+		if wait < 0 {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			next, err := a.client.heartbeat(ctx)
+			if err != nil {
+				a.logger.Error("Heartbeat failed", zap.Error(err))
+				continue
+			}
+			if next != current {
+				a.logger.Info("Schedule updated",
+					zap.String("zone", next.Zone),
+					zap.Bool("enabled", next.Enabled),
+					zap.Duration("interval", next.interval()))
+				current = next
+				reset()
+			}
+		case <-timer.C:
+			if !current.Enabled {
+				continue
+			}
+			lastCheck = time.Now()
+			if next, err := a.check(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				a.logger.Error("Link check failed", zap.Error(err))
+			} else {
+				current = next
+			}
+			reset()
+		}
+	}
+}
+
+func main() {
+	// 加载配置
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化日志
+	var logger *zap.Logger
+	if cfg.Log.Format == "json" {
+		logger, _ = zap.NewProduction()
+	} else {
+		logger, _ = zap.NewDevelopment()
+	}
+	defer logger.Sync()
+
+	if cfg.Agent.ServerURL == "" || cfg.Agent.Token == "" {
+		logger.Fatal("AGENT_SERVER_URL and AGENT_TOKEN are required")
+	}
+
+	// 出站请求的代理、CA 和 User-Agent（同时用于访问服务端和检测链接）
+	if err := httpclient.Init(cfg.Outbound); err != nil {
+		logger.Fatal("Failed to configure outbound HTTP client", zap.Error(err))
+	}
+
+	heartbeatInterval := cfg.Agent.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 30 * time.Second
+	}
+
+	a := &agent{
+		client: &client{
+			baseURL: strings.TrimRight(cfg.Agent.ServerURL, "/"),
+			token:   cfg.Agent.Token,
+			http:    httpclient.New(httpclient.Options{Timeout: 60 * time.Second}),
+		},
+		prober: services.NewProber(cfg.Checker),
+		cfg:    cfg.Checker,
+		logger: logger,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+		logger.Info("Shutting down agent...")
+		cancel()
+	}()
+
+	logger.Info("Agent starting", zap.String("server", a.client.baseURL), zap.String("version", version))
+	a.run(ctx, heartbeatInterval)
+	logger.Info("Agent exited")
+}
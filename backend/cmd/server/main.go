@@ -19,7 +19,10 @@ import (
 	"kk-nav/internal/database"
 	"kk-nav/internal/handlers"
 	adminHandlers "kk-nav/internal/handlers/admin"
+	"kk-nav/internal/httpclient"
+	"kk-nav/internal/metrics"
 	"kk-nav/internal/middleware"
+	"kk-nav/internal/search"
 	"kk-nav/internal/services"
 	"kk-nav/internal/utils"
 
@@ -46,6 +49,11 @@ func main() {
 	// 初始化JWT
 	utils.InitJWT(cfg.JWT.Secret)
 
+	// 出站请求的代理、CA 和 User-Agent
+	if err := httpclient.Init(cfg.Outbound); err != nil {
+		logger.Fatal("Failed to configure outbound HTTP client", zap.Error(err))
+	}
+
 	// 连接数据库
 	if err := database.Connect(cfg); err != nil {
 		logger.Fatal("Failed to connect database", zap.Error(err))
@@ -62,6 +70,16 @@ func main() {
 		logger.Fatal("Failed to initialize data", zap.Error(err))
 	}
 
+	// 全文搜索索引
+	if err := search.Init(database.DB, logger); err != nil {
+		logger.Fatal("Failed to initialize search index", zap.Error(err))
+	}
+
+	// 数据库连接池和链接健康指标
+	if err := metrics.RegisterDB(database.DB); err != nil {
+		logger.Warn("Failed to register database metrics", zap.Error(err))
+	}
+
 	// 设置Gin模式
 	if !cfg.App.Debug {
 		gin.SetMode(gin.ReleaseMode)
@@ -73,6 +91,7 @@ func main() {
 	// 中间件
 	r.Use(gin.Recovery())
 	r.Use(middleware.LoggerMiddleware(logger))
+	r.Use(middleware.MetricsMiddleware())
 	r.Use(middleware.CORSMiddleware())
 
 	// 健康检查
@@ -83,14 +102,29 @@ func main() {
 		})
 	})
 
-	// 注册路由（将在后续实现）
-	registerRoutes(r, logger)
+	// Prometheus 指标（指标中包含内部链接的地址，必须配置 Token 或 IP 白名单）
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Token == "" && len(cfg.Metrics.AllowedIPs) == 0 {
+			logger.Warn("METRICS_ENABLED is set but neither METRICS_TOKEN nor METRICS_ALLOWED_IPS is configured, /metrics is disabled")
+		} else {
+			r.GET("/metrics", middleware.MetricsAuthMiddleware(cfg.Metrics), gin.WrapH(metrics.Handler()))
+		}
+	}
+
+	// 状态变更通知和链接状态检测服务
+	notifier := services.NewNotifier(database.DB, logger)
+	linkChecker := services.NewLinkChecker(database.DB, logger, cfg.Checker, notifier)
+	favicons := services.NewFaviconService(database.DB, logger)
 
-	// 启动链接状态检测服务
-	linkChecker := services.NewLinkChecker(database.DB, logger)
+	// 注册路由
+	registerRoutes(r, logger, linkChecker, notifier, favicons)
+
+	// 启动链接状态检测、图标获取和搜索记录清理任务
 	checkerCtx, checkerCancel := context.WithCancel(context.Background())
 	defer checkerCancel()
 	linkChecker.Start(checkerCtx)
+	favicons.Start(checkerCtx)
+	search.StartPruner(checkerCtx, database.DB)
 
 	// 启动服务器
 	addr := fmt.Sprintf(":%d", cfg.App.Port)
@@ -114,8 +148,9 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
-	// 停止链接检测服务
+	// 停止链接检测和图标获取服务
 	linkChecker.Stop()
+	favicons.Stop()
 	checkerCancel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -129,7 +164,7 @@ func main() {
 }
 
 // registerRoutes 注册路由
-func registerRoutes(r *gin.Engine, logger *zap.Logger) {
+func registerRoutes(r *gin.Engine, logger *zap.Logger, linkChecker *services.LinkChecker, notifier *services.Notifier, favicons *services.FaviconService) {
 	cfg := config.Get()
 	db := database.DB
 
@@ -140,6 +175,12 @@ func registerRoutes(r *gin.Engine, logger *zap.Logger) {
 	tagsHandler := handlers.NewTagsHandler(db)
 	statsHandler := handlers.NewStatsHandler(db)
 	settingsHandler := handlers.NewSettingsHandler(db)
+	statusHandler := handlers.NewStatusHandler(db)
+	searchHandler := handlers.NewSearchHandler(db)
+	goLinksHandler := handlers.NewGoLinksHandler(db)
+
+	// 短链接跳转（/go/<slug>[/<路径>]），登录用户使用其偏好的环境
+	r.GET("/go/*path", middleware.OptionalAuthMiddleware(), goLinksHandler.Redirect)
 
 	// API v1 路由组
 	apiV1 := r.Group("/api/v1")
@@ -150,18 +191,25 @@ func registerRoutes(r *gin.Engine, logger *zap.Logger) {
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/logout", authHandler.Logout)
 			auth.GET("/me", middleware.AuthMiddleware(), authHandler.Me)
+			auth.PUT("/preferences", middleware.AuthMiddleware(), authHandler.UpdatePreferences)
 		}
 
 		// 前台API（不需要认证）
 		apiV1.GET("/categories", categoriesHandler.Index)
 		apiV1.GET("/categories/:id", categoriesHandler.Show)
-		apiV1.GET("/links", linksHandler.Index)
+		apiV1.GET("/links", middleware.OptionalAuthMiddleware(), linksHandler.Index) // 登录后可以用 is:favorite 搜索
 		apiV1.GET("/links/:id", linksHandler.Show)
-		apiV1.POST("/links/:id/click", linksHandler.Click)
+		apiV1.GET("/links/:id/icon", linksHandler.Icon)
+		apiV1.POST("/links/:id/click", middleware.OptionalAuthMiddleware(), linksHandler.Click) // 登录后计入个人点击记录
 		apiV1.GET("/tags", tagsHandler.Index)
 		apiV1.GET("/tags/:id", tagsHandler.Show)
 		apiV1.GET("/stats", statsHandler.Index)
 		apiV1.GET("/settings", settingsHandler.GetPublicSettings)
+		apiV1.GET("/status", statusHandler.Index)
+		apiV1.GET("/search/suggest", middleware.OptionalAuthMiddleware(), searchHandler.Suggest)
+		apiV1.GET("/opensearch.xml", searchHandler.OpenSearch)
+		apiV1.GET("/search/opensearch", middleware.OptionalAuthMiddleware(), searchHandler.OpenSearchSuggest)
+		apiV1.GET("/search/go", middleware.OptionalAuthMiddleware(), searchHandler.Go)
 
 		// 用户相关（需要认证）
 		user := apiV1.Group("", middleware.AuthMiddleware())
@@ -172,17 +220,33 @@ func registerRoutes(r *gin.Engine, logger *zap.Logger) {
 		}
 	}
 
+	// 检测代理API（使用绑定了检测代理的 API Token 认证）
+	agentHandler := handlers.NewAgentHandler(db, linkChecker)
+	agent := r.Group("/api/v1/agent", middleware.AgentMiddleware())
+	{
+		agent.POST("/heartbeat", agentHandler.Heartbeat)
+		agent.GET("/links", agentHandler.Links)
+		agent.POST("/results", agentHandler.Results)
+	}
+
 	// 管理后台API（需要管理员权限）
 	admin := r.Group("/api/v1/admin", middleware.AuthMiddleware(), middleware.AdminMiddleware())
 	{
 		// 初始化管理后台处理器
 		adminDashboardHandler := adminHandlers.NewDashboardHandler(db)
 		adminCategoriesHandler := adminHandlers.NewCategoriesHandler(db)
-		adminLinksHandler := adminHandlers.NewLinksHandler(db)
+		adminLinksHandler := adminHandlers.NewLinksHandler(db, linkChecker, favicons)
 		adminTagsHandler := adminHandlers.NewTagsHandler(db)
 		adminUsersHandler := adminHandlers.NewUsersHandler(db)
-		adminSettingsHandler := adminHandlers.NewSettingsHandler(db)
+		adminSettingsHandler := adminHandlers.NewSettingsHandler(db, linkChecker)
 		adminTokensHandler := adminHandlers.NewTokensHandler(db)
+		adminCheckerHandler := adminHandlers.NewCheckerHandler(linkChecker)
+		adminNotificationsHandler := adminHandlers.NewNotificationsHandler(db, notifier)
+		adminAgentsHandler := adminHandlers.NewAgentsHandler(db)
+		adminMaintenanceHandler := adminHandlers.NewMaintenanceHandler(db)
+		adminSearchAnalyticsHandler := adminHandlers.NewSearchAnalyticsHandler(db)
+		adminSlugsHandler := adminHandlers.NewSlugsHandler(db)
+		adminIconsHandler := adminHandlers.NewIconsHandler(db, favicons)
 
 		// 管理后台首页
 		admin.GET("/dashboard", adminDashboardHandler.Index)
@@ -204,6 +268,16 @@ func registerRoutes(r *gin.Engine, logger *zap.Logger) {
 		admin.DELETE("/links/:id", adminLinksHandler.Delete)
 		admin.POST("/links/:id/check-status", adminLinksHandler.CheckStatus)
 		admin.POST("/links/batch-check", adminLinksHandler.BatchCheckStatus)
+		admin.GET("/links/uptime", adminLinksHandler.UptimeSummary)
+		admin.GET("/links/certificates", adminLinksHandler.Certificates)
+		admin.GET("/links/redirects", adminLinksHandler.Redirects)
+		admin.POST("/links/redirects/accept", adminLinksHandler.BatchAcceptRedirects)
+		admin.POST("/links/:id/accept-redirect", adminLinksHandler.AcceptRedirect)
+		admin.GET("/links/:id/uptime", adminLinksHandler.Uptime)
+		admin.GET("/links/:id/icon", adminIconsHandler.Show)
+		admin.POST("/links/:id/icon", adminIconsHandler.Upload)
+		admin.POST("/links/:id/icon/refresh", adminIconsHandler.Refresh)
+		admin.DELETE("/links/:id/icon", adminIconsHandler.Delete)
 		admin.PATCH("/links/:id/move-up", adminLinksHandler.MoveUp)
 		admin.PATCH("/links/:id/move-down", adminLinksHandler.MoveDown)
 
@@ -224,12 +298,51 @@ func registerRoutes(r *gin.Engine, logger *zap.Logger) {
 		admin.GET("/settings", adminSettingsHandler.Index)
 		admin.PUT("/settings", adminSettingsHandler.Update)
 
+		// 链接检测服务
+		admin.GET("/checker", adminCheckerHandler.Status)
+		admin.POST("/checker/pause", adminCheckerHandler.Pause)
+		admin.POST("/checker/resume", adminCheckerHandler.Resume)
+		admin.POST("/checker/run", adminCheckerHandler.Run)
+		admin.GET("/check-jobs", adminCheckerHandler.Jobs)
+		admin.GET("/check-jobs/:id", adminCheckerHandler.Job)
+		admin.POST("/check-jobs/:id/cancel", adminCheckerHandler.CancelJob)
+
+		// 通知渠道
+		admin.GET("/notifications", adminNotificationsHandler.Index)
+		admin.POST("/notifications", adminNotificationsHandler.Create)
+		admin.GET("/notifications/:id", adminNotificationsHandler.Show)
+		admin.PUT("/notifications/:id", adminNotificationsHandler.Update)
+		admin.DELETE("/notifications/:id", adminNotificationsHandler.Delete)
+		admin.POST("/notifications/:id/test", adminNotificationsHandler.Test)
+
+		// 维护窗口
+		admin.GET("/maintenance-windows", adminMaintenanceHandler.Index)
+		admin.POST("/maintenance-windows", adminMaintenanceHandler.Create)
+		admin.GET("/maintenance-windows/:id", adminMaintenanceHandler.Show)
+		admin.PUT("/maintenance-windows/:id", adminMaintenanceHandler.Update)
+		admin.DELETE("/maintenance-windows/:id", adminMaintenanceHandler.Delete)
+
+		// 检测代理
+		admin.GET("/agents", adminAgentsHandler.Index)
+		admin.POST("/agents", adminAgentsHandler.Create)
+		admin.PUT("/agents/:id", adminAgentsHandler.Update)
+		admin.DELETE("/agents/:id", adminAgentsHandler.Delete)
+
 		// Token 管理
 		admin.GET("/tokens", adminTokensHandler.Index)
 		admin.POST("/tokens", adminTokensHandler.Create)
 		admin.GET("/tokens/:id", adminTokensHandler.Show)
 		admin.PUT("/tokens/:id", adminTokensHandler.Update)
 		admin.DELETE("/tokens/:id", adminTokensHandler.Delete)
+
+		// 搜索分析
+		admin.GET("/search-analytics", adminSearchAnalyticsHandler.Summary)
+		admin.GET("/search-analytics/top-queries", adminSearchAnalyticsHandler.TopQueries)
+		admin.GET("/search-analytics/zero-results", adminSearchAnalyticsHandler.ZeroResults)
+
+		// 短链接名
+		admin.GET("/slugs", adminSlugsHandler.Index)
+		admin.GET("/slugs/check", adminSlugsHandler.Check)
 	}
 
 	// 静态文件服务（前端资源）
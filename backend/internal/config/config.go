@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -22,14 +23,19 @@ type Config struct {
 	JWT      JWTConfig
 	Redis    RedisConfig
 	Log      LogConfig
+	Checker  CheckerConfig
+	Metrics  MetricsConfig
+	Outbound OutboundConfig
+	Agent    AgentConfig
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name  string
-	Env   string
-	Port  int
-	Debug bool
+	Name      string
+	Env       string
+	Port      int
+	Debug     bool
+	PublicURL string // 对外访问地址（如 https://nav.example.com），用于生成绝对地址
 }
 
 // DatabaseConfig 数据库配置
@@ -66,6 +72,36 @@ type LogConfig struct {
 	Format string
 }
 
+// CheckerConfig 链接检测配置
+type CheckerConfig struct {
+	Concurrency        int           // 全局并发检测数
+	PerHostConcurrency int           // 单个主机的最大并发数
+	PerHostInterval    time.Duration // 同一主机两次请求的最小间隔
+	Timeout            time.Duration // 单次请求超时时间
+}
+
+// MetricsConfig Prometheus 指标配置
+type MetricsConfig struct {
+	Enabled    bool     // 默认关闭；开启时必须配置 Token 或 AllowedIPs
+	Token      string   // 不为空时要求 Authorization: Bearer <token>
+	AllowedIPs []string // 不为空时只允许这些 IP / CIDR 访问
+}
+
+// OutboundConfig 出站 HTTP 请求配置（链接检测、通知等）
+type OutboundConfig struct {
+	Proxy     string // http://、https://、socks5:// 代理地址，为空时使用 HTTP_PROXY 等环境变量
+	NoProxy   string // 不走代理的主机，格式同 NO_PROXY
+	CAFile    string // 额外信任的 CA 证书（PEM）
+	UserAgent string
+}
+
+// AgentConfig 远程检测代理配置（cmd/agent 使用）
+type AgentConfig struct {
+	ServerURL         string        // 服务端地址，如 https://nav.example.com
+	Token             string        // 管理后台创建代理时生成的 API Token
+	HeartbeatInterval time.Duration // 心跳间隔
+}
+
 var globalConfig *Config
 
 // Load 加载配置
@@ -91,10 +127,11 @@ func Load() (*Config, error) {
 
 	config := &Config{
 		App: AppConfig{
-			Name:  getString("APP_NAME", "ops-nav"),
-			Env:   getString("APP_ENV", "development"),
-			Port:  getInt("APP_PORT", 8080),
-			Debug: getBool("APP_DEBUG", true),
+			Name:      getString("APP_NAME", "ops-nav"),
+			Env:       getString("APP_ENV", "development"),
+			Port:      getInt("APP_PORT", 8080),
+			Debug:     getBool("APP_DEBUG", true),
+			PublicURL: strings.TrimRight(getString("APP_PUBLIC_URL", ""), "/"),
 		},
 		Database: DatabaseConfig{
 			Type:            getString("DB_TYPE", "postgres"),
@@ -122,6 +159,28 @@ func Load() (*Config, error) {
 			Level:  getString("LOG_LEVEL", "info"),
 			Format: getString("LOG_FORMAT", "json"),
 		},
+		Checker: CheckerConfig{
+			Concurrency:        getInt("CHECKER_CONCURRENCY", 16),
+			PerHostConcurrency: getInt("CHECKER_PER_HOST_CONCURRENCY", 2),
+			PerHostInterval:    time.Duration(getInt("CHECKER_PER_HOST_INTERVAL_MS", 200)) * time.Millisecond,
+			Timeout:            time.Duration(getInt("CHECKER_TIMEOUT", 10)) * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Enabled:    getBool("METRICS_ENABLED", false),
+			Token:      getString("METRICS_TOKEN", ""),
+			AllowedIPs: getList("METRICS_ALLOWED_IPS"),
+		},
+		Outbound: OutboundConfig{
+			Proxy:     getString("OUTBOUND_PROXY", ""),
+			NoProxy:   getString("OUTBOUND_NO_PROXY", ""),
+			CAFile:    getString("OUTBOUND_CA_FILE", ""),
+			UserAgent: getString("OUTBOUND_USER_AGENT", ""),
+		},
+		Agent: AgentConfig{
+			ServerURL:         getString("AGENT_SERVER_URL", ""),
+			Token:             getString("AGENT_TOKEN", ""),
+			HeartbeatInterval: time.Duration(getInt("AGENT_HEARTBEAT_SECONDS", 30)) * time.Second,
+		},
 	}
 
 	globalConfig = config
@@ -152,6 +211,17 @@ func getString(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getList 读取逗号分隔的列表
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(getString(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func getInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -106,6 +106,14 @@ func AutoMigrate() error {
 		&models.ClickLog{},
 		&models.Setting{},
 		&models.APIToken{},
+		&models.LinkCheckResult{},
+		&models.NotificationChannel{},
+		&models.Agent{},
+		&models.MaintenanceWindow{},
+		&models.SearchLog{},
+		&models.LinkEndpoint{},
+		&models.LinkAlias{},
+		&models.LinkIcon{},
 	)
 }
 
@@ -125,6 +133,11 @@ func InitializeData(cfg *config.Config) error {
 		return fmt.Errorf("failed to create default settings: %w", err)
 	}
 
+	// 补全拼音检索词
+	if err := models.FillPinyin(DB); err != nil {
+		return fmt.Errorf("failed to fill pinyin: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,226 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/middleware"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// agentOnlineTimeout 超过该时间没有请求的检测代理视为离线（代理默认每 30 秒发送一次心跳）
+const agentOnlineTimeout = 3 * time.Minute
+
+// AgentsHandler 检测代理管理处理器
+type AgentsHandler struct {
+	db *gorm.DB
+}
+
+// NewAgentsHandler 创建检测代理管理处理器
+func NewAgentsHandler(db *gorm.DB) *AgentsHandler {
+	return &AgentsHandler{db: db}
+}
+
+// agentRequest 创建/更新检测代理的请求
+type agentRequest struct {
+	Name string `json:"name"`
+	Zone string `json:"zone"`
+}
+
+// Index 检测代理列表（含在线状态和所在区域的链接数），以及有链接但没有代理的区域
+func (h *AgentsHandler) Index(c *gin.Context) {
+	var agents []models.Agent
+	if err := h.db.Order("zone, name").Find(&agents).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch agents")
+		return
+	}
+
+	var zones []struct {
+		Zone  string
+		Count int64
+	}
+	h.db.Model(&models.Link{}).
+		Select("zone, COUNT(*) AS count").
+		Where("zone <> ? AND status IN ?", "", models.CheckedLinkStatuses).
+		Group("zone").
+		Scan(&zones)
+	linksByZone := make(map[string]int64, len(zones))
+	for _, z := range zones {
+		linksByZone[z.Zone] = z.Count
+	}
+
+	now := time.Now()
+	covered := make(map[string]bool)
+	items := make([]gin.H, 0, len(agents))
+	for i := range agents {
+		agent := &agents[i]
+		covered[agent.Zone] = true
+		items = append(items, gin.H{
+			"agent":       agent,
+			"online":      agent.IsOnline(now, agentOnlineTimeout),
+			"links_count": linksByZone[agent.Zone],
+		})
+	}
+
+	uncovered := []gin.H{}
+	for _, z := range zones {
+		if !covered[z.Zone] {
+			uncovered = append(uncovered, gin.H{"zone": z.Zone, "links_count": z.Count})
+		}
+	}
+
+	utils.Success(c, gin.H{
+		"agents":          items,
+		"uncovered_zones": uncovered,
+	})
+}
+
+// Create 创建检测代理，同时生成代理使用的 API Token（只在创建时返回）
+func (h *AgentsHandler) Create(c *gin.Context) {
+	var req agentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	agent := models.Agent{
+		Name: strings.TrimSpace(req.Name),
+		Zone: strings.TrimSpace(req.Zone),
+	}
+	if !h.validate(c, &agent) {
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	token := models.APIToken{
+		Name:   "agent: " + agent.Name,
+		UserID: userID,
+		Active: true,
+		Scope:  models.TokenScopeAgent,
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&token).Error; err != nil {
+			return err
+		}
+		agent.APITokenID = token.ID
+		return tx.Create(&agent).Error
+	})
+	if err != nil {
+		utils.InternalServerError(c, "Failed to create agent")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Agent created successfully", gin.H{
+		"agent": agent,
+		"token": token.Token,
+	})
+}
+
+// Update 更新检测代理名称或区域
+func (h *AgentsHandler) Update(c *gin.Context) {
+	agent, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	var req agentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		agent.Name = name
+	}
+	if zone := strings.TrimSpace(req.Zone); zone != "" {
+		agent.Zone = zone
+	}
+	if !h.validate(c, &agent) {
+		return
+	}
+
+	if err := h.db.Model(&agent).Select("name", "zone").Updates(&agent).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update agent")
+		return
+	}
+	h.db.Model(&models.APIToken{}).Where("id = ?", agent.APITokenID).Update("name", "agent: "+agent.Name)
+
+	utils.SuccessWithMessage(c, "Agent updated successfully", gin.H{
+		"agent": agent,
+	})
+}
+
+// Delete 删除检测代理及其 API Token（区域内的链接保持原状态，直到有新的代理接管）
+func (h *AgentsHandler) Delete(c *gin.Context) {
+	agent, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&agent).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.APIToken{}, agent.APITokenID).Error
+	})
+	if err != nil {
+		utils.InternalServerError(c, "Failed to delete agent")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Agent deleted successfully", nil)
+}
+
+// validate 校验名称和区域，失败时已写入响应
+func (h *AgentsHandler) validate(c *gin.Context, agent *models.Agent) bool {
+	if agent.Name == "" || len(agent.Name) > 255 {
+		utils.BadRequest(c, "Agent name is required (max 255 characters)")
+		return false
+	}
+	if err := models.ValidateZone(agent.Zone); err != nil {
+		utils.BadRequest(c, err.Error())
+		return false
+	}
+
+	var count int64
+	h.db.Model(&models.Agent{}).Where("name = ? AND id <> ?", agent.Name, agent.ID).Count(&count)
+	if count > 0 {
+		utils.BadRequest(c, "Agent name already exists")
+		return false
+	}
+	return true
+}
+
+// find 按路径参数查询检测代理，失败时已写入响应
+func (h *AgentsHandler) find(c *gin.Context) (models.Agent, bool) {
+	var agent models.Agent
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid agent ID")
+		return agent, false
+	}
+
+	if err := h.db.First(&agent, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Agent not found")
+			return agent, false
+		}
+		utils.InternalServerError(c, "Database error")
+		return agent, false
+	}
+	return agent, true
+}
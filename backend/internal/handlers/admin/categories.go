@@ -10,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
 	"kk-nav/internal/utils"
 	"gorm.io/gorm"
 )
@@ -115,6 +116,7 @@ func (h *CategoriesHandler) Update(c *gin.Context) {
 		utils.InternalServerError(c, "Failed to update category")
 		return
 	}
+	search.ReindexCategory(h.db, category.ID)
 
 	utils.SuccessWithMessage(c, "Category updated successfully", category)
 }
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"kk-nav/internal/services"
+	"kk-nav/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckerHandler 管理后台链接检测服务处理器
+type CheckerHandler struct {
+	checker *services.LinkChecker
+}
+
+// NewCheckerHandler 创建链接检测服务处理器
+func NewCheckerHandler(checker *services.LinkChecker) *CheckerHandler {
+	return &CheckerHandler{checker: checker}
+}
+
+// Status 检测服务状态（当前进度和上一次检测汇总）
+func (h *CheckerHandler) Status(c *gin.Context) {
+	utils.Success(c, h.checker.Status())
+}
+
+// Pause 暂停定时检测
+func (h *CheckerHandler) Pause(c *gin.Context) {
+	h.checker.Pause()
+	utils.SuccessWithMessage(c, "Link checker paused", h.checker.Status())
+}
+
+// Resume 恢复定时检测
+func (h *CheckerHandler) Resume(c *gin.Context) {
+	h.checker.Resume()
+	utils.SuccessWithMessage(c, "Link checker resumed", h.checker.Status())
+}
+
+// Run 立即执行一次检测
+func (h *CheckerHandler) Run(c *gin.Context) {
+	if !h.checker.RunNow() {
+		utils.Error(c, 409, "Link check is already running")
+		return
+	}
+	utils.SuccessWithMessage(c, "Link check triggered", nil)
+}
+
+// Jobs 批量检测任务列表
+func (h *CheckerHandler) Jobs(c *gin.Context) {
+	utils.Success(c, h.checker.Jobs())
+}
+
+// Job 批量检测任务进度和逐条结果
+func (h *CheckerHandler) Job(c *gin.Context) {
+	job, ok := h.checker.Job(c.Param("id"))
+	if !ok {
+		utils.NotFound(c, "Check job not found")
+		return
+	}
+	utils.Success(c, job)
+}
+
+// CancelJob 取消批量检测任务
+func (h *CheckerHandler) CancelJob(c *gin.Context) {
+	job, ok := h.checker.CancelJob(c.Param("id"))
+	if !ok {
+		utils.NotFound(c, "Check job not found")
+		return
+	}
+	utils.SuccessWithMessage(c, "Check job cancelled", job)
+}
@@ -29,8 +29,10 @@ func (h *DashboardHandler) Index(c *gin.Context) {
 	var stats struct {
 		TotalLinks      int64 `json:"total_links"`
 		ActiveLinks     int64 `json:"active_links"`
+		DegradedLinks   int64 `json:"degraded_links"`
 		InactiveLinks   int64 `json:"inactive_links"`
 		ErrorLinks      int64 `json:"error_links"`
+		CertExpiring    int64 `json:"cert_expiring"`
 		TotalCategories int64 `json:"total_categories"`
 		TotalTags       int64 `json:"total_tags"`
 		TotalUsers      int64 `json:"total_users"`
@@ -43,9 +45,16 @@ func (h *DashboardHandler) Index(c *gin.Context) {
 	// 链接统计
 	h.db.Model(&models.Link{}).Count(&stats.TotalLinks)
 	h.db.Model(&models.Link{}).Where("status = ?", "active").Count(&stats.ActiveLinks)
+	h.db.Model(&models.Link{}).Where("status = ?", "degraded").Count(&stats.DegradedLinks)
 	h.db.Model(&models.Link{}).Where("status = ?", "inactive").Count(&stats.InactiveLinks)
 	h.db.Model(&models.Link{}).Where("status = ?", "error").Count(&stats.ErrorLinks)
 
+	// 证书即将过期（包括已过期）的链接
+	certWarningDays := models.GetSettingInt("cert_warning_days", 14)
+	h.db.Model(&models.Link{}).
+		Where("status <> ? AND cert_expires_at < ?", "inactive", time.Now().AddDate(0, 0, certWarningDays)).
+		Count(&stats.CertExpiring)
+
 	// 分类和标签统计
 	h.db.Model(&models.Category{}).Count(&stats.TotalCategories)
 	h.db.Model(&models.Tag{}).Count(&stats.TotalTags)
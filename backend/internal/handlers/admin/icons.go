@@ -0,0 +1,137 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/services"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// IconsHandler 管理后台链接图标处理器
+type IconsHandler struct {
+	db       *gorm.DB
+	favicons *services.FaviconService
+}
+
+// NewIconsHandler 创建链接图标处理器
+func NewIconsHandler(db *gorm.DB, favicons *services.FaviconService) *IconsHandler {
+	return &IconsHandler{db: db, favicons: favicons}
+}
+
+// Show 链接图标的来源、获取时间和最近一次错误
+func (h *IconsHandler) Show(c *gin.Context) {
+	link, ok := h.findLink(c)
+	if !ok {
+		return
+	}
+
+	var icon models.LinkIcon
+	if err := h.db.Omit("data").Where("link_id = ?", link.ID).First(&icon).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			utils.InternalServerError(c, "Database error")
+			return
+		}
+		utils.Success(c, gin.H{"icon": nil})
+		return
+	}
+	utils.Success(c, gin.H{"icon": icon})
+}
+
+// Upload 上传图标（multipart 字段 file，PNG、JPEG、GIF 或 ICO），替换自动获取的图标且不再自动刷新
+func (h *IconsHandler) Upload(c *gin.Context) {
+	link, ok := h.findLink(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequest(c, "file is required")
+		return
+	}
+	if file.Size > services.MaxIconBytes {
+		utils.BadRequest(c, "Icon file too large")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		utils.BadRequest(c, "Failed to read icon file")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, services.MaxIconBytes))
+	if err != nil {
+		utils.BadRequest(c, "Failed to read icon file")
+		return
+	}
+
+	if err := h.favicons.Upload(link.ID, data); err != nil {
+		utils.BadRequest(c, "Invalid icon: "+err.Error())
+		return
+	}
+	h.Show(c)
+}
+
+// Refresh 立即重新获取图标；已上传的图标需要先删除
+func (h *IconsHandler) Refresh(c *gin.Context) {
+	link, ok := h.findLink(c)
+	if !ok {
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.LinkIcon{}).Where("link_id = ? AND source = ?", link.ID, models.IconSourceUploaded).Count(&count)
+	if count > 0 {
+		utils.BadRequest(c, "Link has an uploaded icon, delete it first")
+		return
+	}
+
+	if err := h.favicons.Fetch(c.Request.Context(), link); err != nil {
+		utils.BadRequest(c, "Failed to fetch icon: "+err.Error())
+		return
+	}
+	h.Show(c)
+}
+
+// Delete 删除图标（包括上传的），恢复自动获取
+func (h *IconsHandler) Delete(c *gin.Context) {
+	link, ok := h.findLink(c)
+	if !ok {
+		return
+	}
+
+	if err := h.favicons.Reset(link.ID); err != nil {
+		utils.InternalServerError(c, "Failed to delete icon")
+		return
+	}
+	utils.SuccessWithMessage(c, "Icon deleted successfully", nil)
+}
+
+// findLink 按路径参数 id 查找链接，失败时已写入响应
+func (h *IconsHandler) findLink(c *gin.Context) (*models.Link, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid link ID")
+		return nil, false
+	}
+
+	var link models.Link
+	if err := h.db.First(&link, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Link not found")
+			return nil, false
+		}
+		utils.InternalServerError(c, "Database error")
+		return nil, false
+	}
+	return &link, true
+}
@@ -6,36 +6,53 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"kk-nav/internal/middleware"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
+	"kk-nav/internal/services"
 	"kk-nav/internal/utils"
-	"gorm.io/gorm"
 )
 
 // LinksHandler 管理后台链接处理器
 type LinksHandler struct {
-	db *gorm.DB
+	db       *gorm.DB
+	checker  *services.LinkChecker
+	favicons *services.FaviconService
 }
 
 // NewLinksHandler 创建链接处理器
-func NewLinksHandler(db *gorm.DB) *LinksHandler {
-	return &LinksHandler{db: db}
+func NewLinksHandler(db *gorm.DB, checker *services.LinkChecker, favicons *services.FaviconService) *LinksHandler {
+	return &LinksHandler{db: db, checker: checker, favicons: favicons}
 }
 
 // Index 链接列表
 func (h *LinksHandler) Index(c *gin.Context) {
 	var links []models.Link
-	query := h.db.Preload("Category").Preload("Tags")
+	query := h.db.Preload("Category").Preload("Tags").Preload("Aliases").Scopes(models.PreloadEndpoints)
 
 	// 搜索
-	if search := c.Query("search"); search != "" {
-		query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	userID, _ := middleware.GetUserID(c)
+	result, err := search.Query(h.db, c.Query("search"), userID)
+	if err != nil {
+		var queryErr *search.QueryError
+		if errors.As(err, &queryErr) {
+			utils.BadRequest(c, queryErr.Error())
+			return
+		}
+		utils.InternalServerError(c, "Search failed")
+		return
+	}
+	if result != nil {
+		query = result.Filter(query)
 	}
 
 	// 分类筛选
@@ -48,6 +65,26 @@ func (h *LinksHandler) Index(c *gin.Context) {
 		query = query.Where("status = ?", status)
 	}
 
+	// 检测区域筛选，central 表示由中心检测服务检测的链接
+	if zone := c.Query("zone"); zone != "" {
+		if zone == "central" {
+			zone = ""
+		}
+		query = query.Where("zone = ?", zone)
+	}
+
+	// 失败原因筛选，多个分类用逗号分隔
+	if errorClass := c.Query("error_class"); errorClass != "" {
+		classes := strings.Split(errorClass, ",")
+		for _, class := range classes {
+			if !models.IsErrorClass(class) {
+				utils.BadRequest(c, "Invalid error_class: "+class)
+				return
+			}
+		}
+		query = query.Where("last_error_class IN ?", classes)
+	}
+
 	// 分页
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
@@ -55,10 +92,31 @@ func (h *LinksHandler) Index(c *gin.Context) {
 
 	var total int64
 	query.Model(&models.Link{}).Count(&total)
+	if result != nil {
+		query = result.Order(query)
+	}
 	query.Order("sort_order").Offset(offset).Limit(pageSize).Find(&links)
+	models.MarkMaintenance(h.db, links)
+	result.Highlight(links)
+
+	// 各失败原因的链接数（不受筛选条件影响）
+	var classCounts []struct {
+		LastErrorClass string
+		Count          int64
+	}
+	h.db.Model(&models.Link{}).
+		Select("last_error_class, COUNT(*) AS count").
+		Where("last_error_class <> ?", "").
+		Group("last_error_class").
+		Scan(&classCounts)
+	errorClasses := make(map[string]int64, len(classCounts))
+	for _, cc := range classCounts {
+		errorClasses[cc.LastErrorClass] = cc.Count
+	}
 
 	utils.Success(c, gin.H{
-		"links": links,
+		"links":         models.NewAdminLinks(links),
+		"error_classes": errorClasses,
 		"pagination": gin.H{
 			"page":       page,
 			"page_size":  pageSize,
@@ -77,7 +135,7 @@ func (h *LinksHandler) Show(c *gin.Context) {
 	}
 
 	var link models.Link
-	if err := h.db.Preload("Category").Preload("Tags").First(&link, id).Error; err != nil {
+	if err := h.db.Preload("Category").Preload("Tags").Preload("Aliases").Scopes(models.PreloadEndpoints).First(&link, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Link not found")
 			return
@@ -87,20 +145,26 @@ func (h *LinksHandler) Show(c *gin.Context) {
 	}
 
 	utils.Success(c, gin.H{
-		"link": link,
+		"link": models.NewAdminLink(link),
 	})
 }
 
 // Create 创建链接
 func (h *LinksHandler) Create(c *gin.Context) {
 	var req struct {
-		Title       string   `json:"title" binding:"required"`
-		URL         string   `json:"url" binding:"required,url"`
-		Description string   `json:"description"`
-		CategoryID  uint     `json:"category_id" binding:"required"`
-		SortOrder   int      `json:"sort_order"`
-		Status      string   `json:"status"`
-		TagNames    []string `json:"tag_names"`
+		Title        string               `json:"title" binding:"required"`
+		URL          string               `json:"url" binding:"required"` // 可以是 URL 模板，按默认值展开后校验
+		Description  string               `json:"description"`
+		CategoryID   uint                 `json:"category_id" binding:"required"`
+		SortOrder    int                  `json:"sort_order"`
+		Status       string               `json:"status"`
+		TagNames     []string             `json:"tag_names"`
+		Probe        *models.LinkProbe    `json:"probe"`
+		Zone         string               `json:"zone"`
+		Endpoints    []endpointRequest    `json:"endpoints"`     // 各环境地址，有默认环境时 url 以默认环境为准
+		URLVariables []models.URLVariable `json:"url_variables"` // URL 模板变量，url 中以 {name} 引用
+		Slug         string               `json:"slug"`          // 短链接名，/go/<slug> 跳转到链接
+		Aliases      []string             `json:"aliases"`       // 短链接别名
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -108,6 +172,24 @@ func (h *LinksHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// 校验探测配置
+	if req.Probe != nil {
+		req.Probe.Normalize()
+		if err := req.Probe.Validate(); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	// 校验检测区域
+	req.Zone = strings.TrimSpace(req.Zone)
+	if req.Zone != "" {
+		if err := models.ValidateZone(req.Zone); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	}
+
 	// 检查标题是否已存在
 	var existingLink models.Link
 	if err := h.db.Where("title = ?", req.Title).First(&existingLink).Error; err == nil {
@@ -144,13 +226,37 @@ func (h *LinksHandler) Create(c *gin.Context) {
 	}
 
 	link := models.Link{
-		Title:       req.Title,
-		URL:         req.URL,
-		Description: req.Description,
-		CategoryID:  req.CategoryID,
-		SortOrder:   req.SortOrder,
-		Status:      req.Status,
-		Tags:        tags,
+		Title:        req.Title,
+		URL:          req.URL,
+		URLVariables: req.URLVariables,
+		Description:  req.Description,
+		CategoryID:   req.CategoryID,
+		SortOrder:    req.SortOrder,
+		Status:       req.Status,
+		Zone:         req.Zone,
+		Tags:         tags,
+	}
+	if req.Probe != nil {
+		link.Probe = *req.Probe
+	}
+	if err := link.SetEndpoints(toEndpoints(req.Endpoints)); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if err := link.ValidateURLTemplate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if u, err := url.Parse(link.DefaultURL()); err != nil || u.Scheme == "" || u.Host == "" {
+		utils.BadRequest(c, "Invalid URL")
+		return
+	}
+	if err := link.SetSlugs(req.Slug, req.Aliases); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if !h.checkSlugs(c, &link) {
+		return
 	}
 
 	if link.Status == "" {
@@ -161,9 +267,11 @@ func (h *LinksHandler) Create(c *gin.Context) {
 		utils.InternalServerError(c, "Failed to create link")
 		return
 	}
+	search.Reindex(h.db, link.ID)
+	h.favicons.Scan()
 
-	h.db.Preload("Category").Preload("Tags").First(&link, link.ID)
-	utils.SuccessWithMessage(c, "Link created successfully", link)
+	h.db.Preload("Category").Preload("Tags").Preload("Aliases").Scopes(models.PreloadEndpoints).First(&link, link.ID)
+	utils.SuccessWithMessage(c, "Link created successfully", models.NewAdminLink(link))
 }
 
 // Update 更新链接
@@ -185,13 +293,19 @@ func (h *LinksHandler) Update(c *gin.Context) {
 	}
 
 	var req struct {
-		Title       string   `json:"title"`
-		URL         string   `json:"url"`
-		Description string   `json:"description"`
-		CategoryID  uint     `json:"category_id"`
-		SortOrder   int      `json:"sort_order"`
-		Status      string   `json:"status"`
-		TagNames    []string `json:"tag_names"`
+		Title        string                `json:"title"`
+		URL          string                `json:"url"`
+		Description  string                `json:"description"`
+		CategoryID   uint                  `json:"category_id"`
+		SortOrder    int                   `json:"sort_order"`
+		Status       string                `json:"status"`
+		TagNames     []string              `json:"tag_names"`
+		Probe        *models.LinkProbe     `json:"probe"`
+		Zone         *string               `json:"zone"`          // 空字符串表示改回中心检测服务
+		Endpoints    *[]endpointRequest    `json:"endpoints"`     // 为空时不修改，空数组表示删除所有环境地址
+		URLVariables *[]models.URLVariable `json:"url_variables"` // 为空时不修改，空数组表示 url 不再是模板
+		Slug         *string               `json:"slug"`          // 为空时不修改，空字符串表示删除短链接名
+		Aliases      *[]string             `json:"aliases"`       // 为空时不修改，空数组表示删除所有别名
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -199,6 +313,26 @@ func (h *LinksHandler) Update(c *gin.Context) {
 		return
 	}
 
+	// 校验探测配置
+	if req.Probe != nil {
+		req.Probe.Normalize()
+		if err := req.Probe.Validate(); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	// 校验检测区域
+	if req.Zone != nil {
+		*req.Zone = strings.TrimSpace(*req.Zone)
+		if *req.Zone != "" {
+			if err := models.ValidateZone(*req.Zone); err != nil {
+				utils.BadRequest(c, err.Error())
+				return
+			}
+		}
+	}
+
 	// 更新字段
 	if req.Title != "" && req.Title != link.Title {
 		// 检查新标题是否已被其他链接使用
@@ -209,6 +343,7 @@ func (h *LinksHandler) Update(c *gin.Context) {
 		}
 		link.Title = req.Title
 	}
+	previousURL := link.DefaultURL()
 	if req.URL != "" {
 		link.URL = req.URL
 	}
@@ -227,8 +362,20 @@ func (h *LinksHandler) Update(c *gin.Context) {
 	if req.SortOrder > 0 {
 		link.SortOrder = req.SortOrder
 	}
-	if req.Status != "" {
+	if req.Status != "" && req.Status != link.Status {
+		// 手动修改状态后重新累计连续成功/失败次数
 		link.Status = req.Status
+		link.ConsecutiveFailures = 0
+		link.ConsecutiveSuccesses = 0
+	}
+	if req.Probe != nil {
+		link.Probe = *req.Probe
+	}
+	if req.Zone != nil && *req.Zone != link.Zone {
+		// 换由其他检测方负责后重新累计连续成功/失败次数
+		link.Zone = *req.Zone
+		link.ConsecutiveFailures = 0
+		link.ConsecutiveSuccesses = 0
 	}
 
 	// 更新标签
@@ -253,13 +400,142 @@ func (h *LinksHandler) Update(c *gin.Context) {
 		link.Tags = tags
 	}
 
-	if err := h.db.Save(&link).Error; err != nil {
+	// 更新环境地址，沿用环境和地址都未变化的检测结果
+	var existing []models.LinkEndpoint
+	h.db.Where("link_id = ?", link.ID).Find(&existing)
+	if req.Endpoints != nil {
+		if err := link.SetEndpoints(toEndpoints(*req.Endpoints)); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+		carryEndpointStatus(link.Endpoints, existing)
+	}
+
+	// 更新 URL 模板变量，连同未修改的环境地址一起校验
+	if req.URLVariables != nil {
+		link.URLVariables = *req.URLVariables
+	}
+	check := link
+	if req.Endpoints == nil {
+		check.Endpoints = existing
+	}
+	if err := check.ValidateURLTemplate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	urlChanged := link.DefaultURL() != previousURL
+	if urlChanged {
+		link.Redirect = models.LinkRedirect{}
+	}
+
+	// 更新短链接名和别名，未传入的部分保持不变
+	if req.Slug != nil || req.Aliases != nil {
+		h.db.Where("link_id = ?", link.ID).Find(&link.Aliases)
+		slug := ""
+		if link.Slug != nil {
+			slug = *link.Slug
+		}
+		if req.Slug != nil {
+			slug = *req.Slug
+		}
+		aliases := make([]string, 0, len(link.Aliases))
+		for _, a := range link.Aliases {
+			aliases = append(aliases, a.Slug)
+		}
+		if req.Aliases != nil {
+			aliases = *req.Aliases
+		}
+		if err := link.SetSlugs(slug, aliases); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+		if !h.checkSlugs(c, &link) {
+			return
+		}
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if req.Endpoints != nil {
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkEndpoint{}).Error; err != nil {
+				return err
+			}
+		}
+		if req.Slug != nil || req.Aliases != nil {
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkAlias{}).Error; err != nil {
+				return err
+			}
+		}
+		// icon_hash 由图标服务维护
+		return tx.Omit("icon_hash").Save(&link).Error
+	})
+	if err != nil {
 		utils.InternalServerError(c, "Failed to update link")
 		return
 	}
+	search.Reindex(h.db, link.ID)
+
+	// 地址变化后重新获取自动获取的图标（获取到之前仍显示旧图标），上传的图标保持不变
+	if urlChanged {
+		h.db.Model(&models.LinkIcon{}).
+			Where("link_id = ? AND source = ?", link.ID, models.IconSourceFetched).
+			Update("fetched_at", nil)
+		h.favicons.Scan()
+	}
+
+	h.db.Preload("Category").Preload("Tags").Preload("Aliases").Scopes(models.PreloadEndpoints).First(&link, link.ID)
+	utils.SuccessWithMessage(c, "Link updated successfully", models.NewAdminLink(link))
+}
+
+// checkSlugs 短链接名或别名已被其他链接使用时返回 400 和冲突列表
+func (h *LinksHandler) checkSlugs(c *gin.Context, link *models.Link) bool {
+	conflicts, err := models.FindSlugConflicts(h.db, link.ID, link.Slugs())
+	if err != nil {
+		utils.InternalServerError(c, "Database error")
+		return false
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusBadRequest, utils.Response{
+			Code:    http.StatusBadRequest,
+			Message: "Slug already in use: " + conflicts[0].Slug,
+			Data:    gin.H{"conflicts": conflicts},
+		})
+		return false
+	}
+	return true
+}
+
+// endpointRequest 环境地址请求参数
+type endpointRequest struct {
+	Environment string `json:"environment"`
+	URL         string `json:"url"`
+	IsDefault   bool   `json:"is_default"`
+}
+
+// toEndpoints 转换为环境地址（规范化和校验由 Link.SetEndpoints 完成）
+func toEndpoints(reqs []endpointRequest) []models.LinkEndpoint {
+	if len(reqs) == 0 {
+		return nil
+	}
+	endpoints := make([]models.LinkEndpoint, len(reqs))
+	for i, r := range reqs {
+		endpoints[i] = models.LinkEndpoint{Environment: r.Environment, URL: r.URL, IsDefault: r.IsDefault}
+	}
+	return endpoints
+}
 
-	h.db.Preload("Category").Preload("Tags").First(&link, link.ID)
-	utils.SuccessWithMessage(c, "Link updated successfully", link)
+// carryEndpointStatus 环境和地址都未变化时沿用原来的检测结果
+func carryEndpointStatus(endpoints, existing []models.LinkEndpoint) {
+	for i := range endpoints {
+		for _, old := range existing {
+			if old.Environment == endpoints[i].Environment && old.URL == endpoints[i].URL {
+				e := &endpoints[i]
+				e.Status, e.LastCheckedAt, e.LastStatusCode = old.Status, old.LastCheckedAt, old.LastStatusCode
+				e.LastLatencyMs, e.LastErrorClass, e.LastError = old.LastLatencyMs, old.LastErrorClass, old.LastError
+				e.CreatedAt = old.CreatedAt
+				break
+			}
+		}
+	}
 }
 
 // Delete 删除链接
@@ -275,6 +551,13 @@ func (h *LinksHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	// 清理检测历史、环境地址、别名、图标和搜索索引
+	h.db.Where("link_id = ?", id).Delete(&models.LinkCheckResult{})
+	h.db.Where("link_id = ?", id).Delete(&models.LinkEndpoint{})
+	h.db.Where("link_id = ?", id).Delete(&models.LinkAlias{})
+	h.db.Where("link_id = ?", id).Delete(&models.LinkIcon{})
+	search.Reindex(h.db, uint(id))
+
 	utils.SuccessWithMessage(c, "Link deleted successfully", nil)
 }
 
@@ -296,24 +579,26 @@ func (h *LinksHandler) CheckStatus(c *gin.Context) {
 		return
 	}
 
-	// 检测链接状态
-	status := h.checkLinkStatus(link.URL)
-	now := time.Now()
-	link.Status = status
-	link.LastCheckedAt = &now
-
-	if err := h.db.Save(&link).Error; err != nil {
-		utils.InternalServerError(c, "Failed to update link status")
+	// 隔离网络中的链接只能由所在区域的检测代理访问
+	if link.Zone != "" {
+		utils.BadRequest(c, "Link is checked by the agent of zone "+link.Zone)
 		return
 	}
 
+	// 检测链接状态
+	result := h.checker.CheckLink(c.Request.Context(), &link)
+
 	utils.Success(c, gin.H{
-		"link":   link,
-		"status": status,
+		"link":        models.NewAdminLink(link),
+		"status":      result.Status,
+		"status_code": result.StatusCode,
+		"latency_ms":  result.Latency.Milliseconds(),
+		"error_class": result.ErrorClass,
+		"error":       result.ErrorMessage(),
 	})
 }
 
-// BatchCheckStatus 批量检测链接状态
+// BatchCheckStatus 批量检测链接状态（后台任务，立即返回任务ID，通过 /admin/check-jobs/:id 查询进度）
 func (h *LinksHandler) BatchCheckStatus(c *gin.Context) {
 	var req struct {
 		LinkIDs []uint `json:"link_ids"`
@@ -329,48 +614,364 @@ func (h *LinksHandler) BatchCheckStatus(c *gin.Context) {
 		return
 	}
 
+	// 跳过由检测代理负责的链接
 	var links []models.Link
-	h.db.Where("id IN ?", req.LinkIDs).Find(&links)
+	if err := h.db.Where("id IN ? AND zone = ?", req.LinkIDs, "").Find(&links).Error; err != nil {
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+	if len(links) == 0 {
+		utils.NotFound(c, "No centrally checked links found")
+		return
+	}
+
+	job := h.checker.StartJob(links)
+	utils.SuccessWithMessage(c, "Batch check started", gin.H{
+		"job_id": job.ID,
+		"job":    job,
+	})
+}
+
+// UptimeSummary 所有链接在时间窗口内的可用率
+func (h *LinksHandler) UptimeSummary(c *gin.Context) {
+	window, err := utils.ParseWindow(c.DefaultQuery("window", "7d"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	since := time.Now().Add(-window)
+
+	var rows []struct {
+		LinkID       uint    `json:"link_id"`
+		Total        int64   `json:"total_checks"`
+		Success      int64   `json:"success_checks"`
+		AvgLatencyMs float64 `json:"avg_latency_ms"`
+	}
+	h.db.Model(&models.LinkCheckResult{}).
+		Select("link_id, COUNT(*) AS total, SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END) AS success, AVG(latency_ms) AS avg_latency_ms").
+		Where("checked_at >= ?", since).
+		Group("link_id").
+		Scan(&rows)
+
+	var links []models.Link
+	h.db.Select("id, title, url, status, last_checked_at").Order("sort_order").Find(&links)
+
+	stats := make(map[uint]int, len(rows))
+	for i, row := range rows {
+		stats[row.LinkID] = i
+	}
+
+	items := make([]gin.H, 0, len(links))
+	for _, link := range links {
+		item := gin.H{
+			"link_id":         link.ID,
+			"title":           link.Title,
+			"url":             link.URL,
+			"status":          link.Status,
+			"last_checked_at": link.LastCheckedAt,
+			"total_checks":    int64(0),
+			"uptime":          nil,
+		}
+		if i, ok := stats[link.ID]; ok && rows[i].Total > 0 {
+			item["total_checks"] = rows[i].Total
+			item["uptime"] = utils.Percent(rows[i].Success, rows[i].Total)
+			item["avg_latency_ms"] = rows[i].AvgLatencyMs
+		}
+		items = append(items, item)
+	}
+
+	utils.Success(c, gin.H{
+		"window": window.String(),
+		"since":  since,
+		"links":  items,
+	})
+}
+
+// Certificates 按证书剩余天数列出 HTTPS 链接
+func (h *LinksHandler) Certificates(c *gin.Context) {
+	warningDays := models.GetSettingInt("cert_warning_days", 14)
+
+	query := h.db.Model(&models.Link{}).Where("cert_expires_at IS NOT NULL")
+
+	// 只看指定天数内过期的证书
+	if within := c.Query("within_days"); within != "" {
+		days, err := strconv.Atoi(within)
+		if err != nil {
+			utils.BadRequest(c, "Invalid within_days")
+			return
+		}
+		query = query.Where("cert_expires_at < ?", time.Now().AddDate(0, 0, days))
+	}
+
+	var links []models.Link
+	query.Order("cert_expires_at").Find(&links)
 
 	now := time.Now()
-	results := make([]gin.H, 0, len(links))
+	items := make([]gin.H, 0, len(links))
+	for _, link := range links {
+		items = append(items, gin.H{
+			"link_id":        link.ID,
+			"title":          link.Title,
+			"url":            link.URL,
+			"status":         link.Status,
+			"expires_at":     link.Certificate.ExpiresAt,
+			"days_remaining": link.Certificate.DaysRemaining(now),
+			"issuer":         link.Certificate.Issuer,
+			"subject":        link.Certificate.Subject,
+			"hostname_match": link.Certificate.HostnameMatch,
+			"checked_at":     link.Certificate.CheckedAt,
+			"expiring":       link.Certificate.IsExpiring(now, warningDays),
+		})
+	}
+
+	utils.Success(c, gin.H{
+		"certificates": items,
+		"total":        len(items),
+		"warning_days": warningDays,
+	})
+}
+
+// Redirects 发生重定向的链接（默认只列出永久重定向，?all=true 包含临时重定向）
+func (h *LinksHandler) Redirects(c *gin.Context) {
+	query := h.db.Model(&models.Link{}).Preload("Category")
+	if c.Query("all") == "true" {
+		query = query.Where("redirect_final_url <> ?", "")
+	} else {
+		query = query.Where("redirect_permanent = ?", true)
+	}
 
+	var links []models.Link
+	query.Order("id").Find(&links)
+
+	items := make([]gin.H, 0, len(links))
 	for _, link := range links {
-		status := h.checkLinkStatus(link.URL)
-		link.Status = status
-		link.LastCheckedAt = &now
-		h.db.Save(&link)
-
-		results = append(results, gin.H{
-			"id":     link.ID,
-			"title":  link.Title,
-			"url":    link.URL,
-			"status": status,
+		items = append(items, gin.H{
+			"link_id":    link.ID,
+			"title":      link.Title,
+			"url":        link.URL,
+			"category":   link.Category.Name,
+			"status":     link.Status,
+			"final_url":  link.Redirect.FinalURL,
+			"chain":      link.Redirect.Chain,
+			"permanent":  link.Redirect.Permanent,
+			"checked_at": link.Redirect.CheckedAt,
 		})
 	}
 
-	utils.SuccessWithMessage(c, "Batch check completed", gin.H{
-		"results": results,
-		"total":   len(results),
+	utils.Success(c, gin.H{
+		"redirects": items,
+		"total":     len(items),
 	})
 }
 
-// checkLinkStatus 检测链接状态
-func (h *LinksHandler) checkLinkStatus(url string) string {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// AcceptRedirect 把链接地址更新为重定向后的最终地址
+func (h *LinksHandler) AcceptRedirect(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid link ID")
+		return
+	}
+
+	var link models.Link
+	if err := h.db.First(&link, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Link not found")
+			return
+		}
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+
+	if link.Redirect.FinalURL == "" {
+		utils.BadRequest(c, "Link has no redirect to accept")
+		return
+	}
+	// 最终地址是按默认值展开后的结果，不能替换模板
+	if link.IsTemplate() {
+		utils.BadRequest(c, "Link URL is a template, update it manually")
+		return
+	}
+
+	if err := h.acceptRedirect(&link); err != nil {
+		utils.InternalServerError(c, "Failed to update link")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Link URL updated", models.NewAdminLink(link))
+}
+
+// BatchAcceptRedirects 批量接受重定向（只处理永久重定向的链接）
+func (h *LinksHandler) BatchAcceptRedirects(c *gin.Context) {
+	var req struct {
+		LinkIDs []uint `json:"link_ids"`
 	}
 
-	resp, err := client.Get(url)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	if len(req.LinkIDs) == 0 {
+		utils.BadRequest(c, "Link IDs required")
+		return
+	}
+
+	var links []models.Link
+	h.db.Where("id IN ? AND redirect_permanent = ?", req.LinkIDs, true).Find(&links)
+
+	updated := make([]gin.H, 0, len(links))
+	for i := range links {
+		oldURL := links[i].URL
+		if links[i].IsTemplate() {
+			continue
+		}
+		if err := h.acceptRedirect(&links[i]); err != nil {
+			continue
+		}
+		updated = append(updated, gin.H{
+			"link_id": links[i].ID,
+			"old_url": oldURL,
+			"url":     links[i].URL,
+		})
+	}
+
+	utils.SuccessWithMessage(c, "Link URLs updated", gin.H{
+		"updated": updated,
+		"total":   len(updated),
+		"skipped": len(req.LinkIDs) - len(updated),
+	})
+}
+
+// acceptRedirect 用最终地址替换链接地址并清除重定向记录；有默认环境时同时更新默认环境的地址，
+// 否则跳转仍使用旧地址，下次编辑链接时也会恢复为旧地址
+func (h *LinksHandler) acceptRedirect(link *models.Link) error {
+	link.URL = link.Redirect.FinalURL
+	link.Redirect = models.LinkRedirect{}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(link).
+			Select("url", "redirect_final_url", "redirect_chain", "redirect_permanent", "redirect_checked_at").
+			Updates(link).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.LinkEndpoint{}).
+			Where("link_id = ? AND is_default = ?", link.ID, true).
+			Update("url", link.URL).Error
+	})
 	if err != nil {
-		return "error"
+		return err
+	}
+	search.Reindex(h.db, link.ID)
+	return nil
+}
+
+// Uptime 单个链接在时间窗口内的可用率和时间线
+func (h *LinksHandler) Uptime(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid link ID")
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return "active"
+	window, err := utils.ParseWindow(c.DefaultQuery("window", "24h"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	buckets, _ := strconv.Atoi(c.DefaultQuery("buckets", "24"))
+	if buckets <= 0 || buckets > 500 {
+		utils.BadRequest(c, "buckets must be between 1 and 500")
+		return
+	}
+	step := window / time.Duration(buckets)
+	if step <= 0 {
+		utils.BadRequest(c, "window is too short for the number of buckets")
+		return
 	}
-	return "error"
+
+	var link models.Link
+	if err := h.db.First(&link, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Link not found")
+			return
+		}
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	var results []models.LinkCheckResult
+	h.db.Where("link_id = ? AND checked_at >= ?", link.ID, since).
+		Order("checked_at").
+		Find(&results)
+
+	// 按时间桶聚合
+	type bucket struct {
+		Start        time.Time `json:"start"`
+		End          time.Time `json:"end"`
+		Checks       int64     `json:"checks"`
+		Success      int64     `json:"success"`
+		Uptime       *float64  `json:"uptime"`
+		AvgLatencyMs float64   `json:"avg_latency_ms"`
+		latencySum   int64
+	}
+	timeline := make([]bucket, buckets)
+	for i := range timeline {
+		timeline[i].Start = since.Add(step * time.Duration(i))
+		timeline[i].End = timeline[i].Start.Add(step)
+	}
+
+	var success, latencySum int64
+	for _, r := range results {
+		i := int(r.CheckedAt.Sub(since) / step)
+		if i >= buckets {
+			i = buckets - 1
+		}
+		timeline[i].Checks++
+		timeline[i].latencySum += r.LatencyMs
+		latencySum += r.LatencyMs
+		if r.IsUp() {
+			timeline[i].Success++
+			success++
+		}
+	}
+	for i := range timeline {
+		if timeline[i].Checks > 0 {
+			uptime := utils.Percent(timeline[i].Success, timeline[i].Checks)
+			timeline[i].Uptime = &uptime
+			timeline[i].AvgLatencyMs = float64(timeline[i].latencySum) / float64(timeline[i].Checks)
+		}
+	}
+
+	summary := gin.H{
+		"total_checks":   len(results),
+		"success_checks": success,
+		"uptime":         nil,
+	}
+	if len(results) > 0 {
+		summary["uptime"] = utils.Percent(success, int64(len(results)))
+		summary["avg_latency_ms"] = float64(latencySum) / float64(len(results))
+	}
+
+	// 最近的失败记录
+	var failures []models.LinkCheckResult
+	h.db.Where("link_id = ? AND checked_at >= ? AND status <> ?", link.ID, since, "active").
+		Order("checked_at DESC").
+		Limit(20).
+		Find(&failures)
+
+	utils.Success(c, gin.H{
+		"link_id":         link.ID,
+		"title":           link.Title,
+		"status":          link.Status,
+		"window":          window.String(),
+		"since":           since,
+		"summary":         summary,
+		"timeline":        timeline,
+		"recent_failures": failures,
+	})
 }
 
 // MoveUp 上移
@@ -397,8 +998,9 @@ func (h *LinksHandler) MoveUp(c *gin.Context) {
 
 	// 交换排序
 	link.SortOrder, prevLink.SortOrder = prevLink.SortOrder, link.SortOrder
-	h.db.Save(&link)
-	h.db.Save(&prevLink)
+	// 只更新排序，避免覆盖并发写入的其他字段（如图标服务更新的 icon_hash）
+	h.db.Model(&link).UpdateColumn("sort_order", link.SortOrder)
+	h.db.Model(&prevLink).UpdateColumn("sort_order", prevLink.SortOrder)
 
 	utils.SuccessWithMessage(c, "Link moved up successfully", nil)
 }
@@ -427,9 +1029,9 @@ func (h *LinksHandler) MoveDown(c *gin.Context) {
 
 	// 交换排序
 	link.SortOrder, nextLink.SortOrder = nextLink.SortOrder, link.SortOrder
-	h.db.Save(&link)
-	h.db.Save(&nextLink)
+	// 只更新排序，避免覆盖并发写入的其他字段（如图标服务更新的 icon_hash）
+	h.db.Model(&link).UpdateColumn("sort_order", link.SortOrder)
+	h.db.Model(&nextLink).UpdateColumn("sort_order", nextLink.SortOrder)
 
 	utils.SuccessWithMessage(c, "Link moved down successfully", nil)
 }
-
@@ -0,0 +1,223 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// MaintenanceHandler 维护窗口处理器
+type MaintenanceHandler struct {
+	db *gorm.DB
+}
+
+// NewMaintenanceHandler 创建维护窗口处理器
+func NewMaintenanceHandler(db *gorm.DB) *MaintenanceHandler {
+	return &MaintenanceHandler{db: db}
+}
+
+// maintenanceRequest 创建/更新维护窗口的请求（为空的字段在更新时保持不变）
+type maintenanceRequest struct {
+	Name            string     `json:"name"`
+	Message         *string    `json:"message"`
+	Mode            string     `json:"mode"`
+	Enabled         *bool      `json:"enabled"`
+	StartsAt        *time.Time `json:"starts_at"`
+	EndsAt          *time.Time `json:"ends_at"`
+	Weekdays        *[]int     `json:"weekdays"`
+	StartTime       *string    `json:"start_time"` // 设置为空字符串时改为一次性窗口
+	DurationMinutes *int       `json:"duration_minutes"`
+	Timezone        *string    `json:"timezone"`
+	LinkIDs         *[]uint    `json:"link_ids"`
+	CategoryIDs     *[]uint    `json:"category_ids"`
+	TagIDs          *[]uint    `json:"tag_ids"`
+}
+
+// apply 把请求中的字段写入维护窗口
+func (req *maintenanceRequest) apply(window *models.MaintenanceWindow) {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		window.Name = name
+	}
+	if req.Message != nil {
+		window.Message = *req.Message
+	}
+	if req.Mode != "" {
+		window.Mode = req.Mode
+	}
+	if req.Enabled != nil {
+		window.Enabled = *req.Enabled
+	}
+	if req.StartsAt != nil {
+		window.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		window.EndsAt = req.EndsAt
+	}
+	if req.Weekdays != nil {
+		window.Weekdays = *req.Weekdays
+	}
+	if req.StartTime != nil {
+		window.StartTime = *req.StartTime
+	}
+	if req.DurationMinutes != nil {
+		window.DurationMinutes = *req.DurationMinutes
+	}
+	if req.Timezone != nil {
+		window.Timezone = *req.Timezone
+	}
+	if req.LinkIDs != nil {
+		window.LinkIDs = *req.LinkIDs
+	}
+	if req.CategoryIDs != nil {
+		window.CategoryIDs = *req.CategoryIDs
+	}
+	if req.TagIDs != nil {
+		window.TagIDs = *req.TagIDs
+	}
+}
+
+// Index 维护窗口列表（?active=true 只返回当前生效的窗口）
+func (h *MaintenanceHandler) Index(c *gin.Context) {
+	var windows []models.MaintenanceWindow
+	if err := h.db.Order("created_at DESC").Find(&windows).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch maintenance windows")
+		return
+	}
+
+	now := time.Now()
+	onlyActive := c.Query("active") == "true"
+	items := make([]gin.H, 0, len(windows))
+	for i := range windows {
+		active := windows[i].ActiveAt(now)
+		if onlyActive && !active {
+			continue
+		}
+		items = append(items, gin.H{
+			"window": windows[i],
+			"active": active,
+		})
+	}
+
+	utils.Success(c, gin.H{
+		"windows": items,
+	})
+}
+
+// Show 维护窗口详情
+func (h *MaintenanceHandler) Show(c *gin.Context) {
+	window, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"window": window,
+		"active": window.ActiveAt(time.Now()),
+	})
+}
+
+// Create 创建维护窗口
+func (h *MaintenanceHandler) Create(c *gin.Context) {
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	window := models.MaintenanceWindow{
+		Mode:    models.MaintenanceFreeze,
+		Enabled: true,
+	}
+	req.apply(&window)
+	if err := window.Validate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.db.Create(&window).Error; err != nil {
+		utils.InternalServerError(c, "Failed to create maintenance window")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Maintenance window created successfully", gin.H{
+		"window": window,
+		"active": window.ActiveAt(time.Now()),
+	})
+}
+
+// Update 更新维护窗口
+func (h *MaintenanceHandler) Update(c *gin.Context) {
+	window, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	var req maintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	req.apply(&window)
+	if err := window.Validate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.db.Save(&window).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update maintenance window")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Maintenance window updated successfully", gin.H{
+		"window": window,
+		"active": window.ActiveAt(time.Now()),
+	})
+}
+
+// Delete 删除维护窗口
+func (h *MaintenanceHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid maintenance window ID")
+		return
+	}
+
+	if err := h.db.Delete(&models.MaintenanceWindow{}, id).Error; err != nil {
+		utils.InternalServerError(c, "Failed to delete maintenance window")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Maintenance window deleted successfully", nil)
+}
+
+// find 按路径参数查询维护窗口，失败时已写入响应
+func (h *MaintenanceHandler) find(c *gin.Context) (models.MaintenanceWindow, bool) {
+	var window models.MaintenanceWindow
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid maintenance window ID")
+		return window, false
+	}
+
+	if err := h.db.First(&window, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Maintenance window not found")
+			return window, false
+		}
+		utils.InternalServerError(c, "Database error")
+		return window, false
+	}
+	return window, true
+}
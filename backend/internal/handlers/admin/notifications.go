@@ -0,0 +1,220 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/services"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// NotificationsHandler 通知渠道处理器
+type NotificationsHandler struct {
+	db       *gorm.DB
+	notifier *services.Notifier
+}
+
+// NewNotificationsHandler 创建通知渠道处理器
+func NewNotificationsHandler(db *gorm.DB, notifier *services.Notifier) *NotificationsHandler {
+	return &NotificationsHandler{db: db, notifier: notifier}
+}
+
+// notificationRequest 创建/更新通知渠道的请求（为空的字段在更新时保持不变，
+// 配置中的密钥和密码为空或为占位值时保持不变）
+type notificationRequest struct {
+	Name           string                     `json:"name"`
+	Type           string                     `json:"type"`
+	Enabled        *bool                      `json:"enabled"`
+	Config         *models.NotificationConfig `json:"config"`
+	Template       *string                    `json:"template"`
+	NotifyDown     *bool                      `json:"notify_down"`
+	NotifyRecovery *bool                      `json:"notify_recovery"`
+	CategoryIDs    *[]uint                    `json:"category_ids"`
+	TagIDs         *[]uint                    `json:"tag_ids"`
+}
+
+// apply 把请求中的字段写入渠道
+func (req *notificationRequest) apply(channel *models.NotificationChannel) {
+	if req.Name != "" {
+		channel.Name = req.Name
+	}
+	if req.Type != "" {
+		channel.Type = req.Type
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+	if req.Config != nil {
+		config := *req.Config
+		config.KeepSecrets(channel.Config)
+		channel.Config = config
+	}
+	if req.Template != nil {
+		channel.Template = *req.Template
+	}
+	if req.NotifyDown != nil {
+		channel.NotifyDown = *req.NotifyDown
+	}
+	if req.NotifyRecovery != nil {
+		channel.NotifyRecovery = *req.NotifyRecovery
+	}
+	if req.CategoryIDs != nil {
+		channel.CategoryIDs = *req.CategoryIDs
+	}
+	if req.TagIDs != nil {
+		channel.TagIDs = *req.TagIDs
+	}
+}
+
+// Index 通知渠道列表
+func (h *NotificationsHandler) Index(c *gin.Context) {
+	var channels []models.NotificationChannel
+	h.db.Order("id ASC").Find(&channels)
+	for i := range channels {
+		channels[i] = channels[i].Masked()
+	}
+
+	utils.Success(c, gin.H{
+		"channels":         channels,
+		"default_template": services.DefaultNotificationTemplate,
+	})
+}
+
+// Show 通知渠道详情
+func (h *NotificationsHandler) Show(c *gin.Context) {
+	channel, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"channel": channel.Masked(),
+	})
+}
+
+// Create 创建通知渠道
+func (h *NotificationsHandler) Create(c *gin.Context) {
+	var req notificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	channel := models.NotificationChannel{
+		Enabled:        true,
+		NotifyDown:     true,
+		NotifyRecovery: true,
+	}
+	req.apply(&channel)
+	if err := channel.Validate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.NotificationChannel{}).Where("name = ?", channel.Name).Count(&count)
+	if count > 0 {
+		utils.BadRequest(c, "Channel name already exists")
+		return
+	}
+
+	if err := h.db.Create(&channel).Error; err != nil {
+		utils.InternalServerError(c, "Failed to create channel")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Channel created successfully", channel.Masked())
+}
+
+// Update 更新通知渠道
+func (h *NotificationsHandler) Update(c *gin.Context) {
+	channel, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	var req notificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	req.apply(&channel)
+	if err := channel.Validate(); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.NotificationChannel{}).Where("name = ? AND id <> ?", channel.Name, channel.ID).Count(&count)
+	if count > 0 {
+		utils.BadRequest(c, "Channel name already exists")
+		return
+	}
+
+	if err := h.db.Save(&channel).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update channel")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Channel updated successfully", channel.Masked())
+}
+
+// Delete 删除通知渠道
+func (h *NotificationsHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid channel ID")
+		return
+	}
+
+	if err := h.db.Delete(&models.NotificationChannel{}, id).Error; err != nil {
+		utils.InternalServerError(c, "Failed to delete channel")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Channel deleted successfully", nil)
+}
+
+// Test 发送测试通知（使用示例事件，不受路由规则和事件开关限制）
+func (h *NotificationsHandler) Test(c *gin.Context) {
+	channel, ok := h.find(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notifier.SendTest(c.Request.Context(), &channel); err != nil {
+		utils.Error(c, 502, "Failed to send test notification: "+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Test notification sent", nil)
+}
+
+// find 按路径参数查询通知渠道，失败时已写入响应
+func (h *NotificationsHandler) find(c *gin.Context) (models.NotificationChannel, bool) {
+	var channel models.NotificationChannel
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid channel ID")
+		return channel, false
+	}
+
+	if err := h.db.First(&channel, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Channel not found")
+			return channel, false
+		}
+		utils.InternalServerError(c, "Database error")
+		return channel, false
+	}
+	return channel, true
+}
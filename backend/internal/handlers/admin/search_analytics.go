@@ -0,0 +1,159 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxAnalyticsLimit 排行榜最多返回的条数
+const maxAnalyticsLimit = 100
+
+// SearchAnalyticsHandler 搜索分析处理器
+type SearchAnalyticsHandler struct {
+	db *gorm.DB
+}
+
+// NewSearchAnalyticsHandler 创建搜索分析处理器
+func NewSearchAnalyticsHandler(db *gorm.DB) *SearchAnalyticsHandler {
+	return &SearchAnalyticsHandler{db: db}
+}
+
+// queryStat 按搜索语句聚合的统计
+type queryStat struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	Users          int64     `json:"users"` // 登录用户数
+	AvgResults     float64   `json:"avg_results"`
+	Clicks         int64     `json:"clicks"`
+	CTR            float64   `json:"ctr"` // 点击率（%）
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// Summary 时间窗口内的搜索概况：搜索次数、无结果率和点击率
+func (h *SearchAnalyticsHandler) Summary(c *gin.Context) {
+	window, since, ok := h.window(c)
+	if !ok {
+		return
+	}
+
+	var stats struct {
+		Searches        int64 `json:"searches"`
+		UniqueQueries   int64 `json:"unique_queries"`
+		ZeroResults     int64 `json:"zero_results"`
+		ClickedSearches int64 `json:"clicked_searches"`
+	}
+	h.db.Model(&models.SearchLog{}).
+		Select("COUNT(*) AS searches, COUNT(DISTINCT query) AS unique_queries, "+
+			"SUM(CASE WHEN result_count = 0 THEN 1 ELSE 0 END) AS zero_results, "+
+			"SUM(CASE WHEN clicked_link_id IS NOT NULL THEN 1 ELSE 0 END) AS clicked_searches").
+		Where("created_at >= ?", since).
+		Scan(&stats)
+
+	utils.Success(c, gin.H{
+		"window":           window.String(),
+		"since":            since,
+		"enabled":          models.GetSettingBool("enable_analytics", true),
+		"searches":         stats.Searches,
+		"unique_queries":   stats.UniqueQueries,
+		"zero_results":     stats.ZeroResults,
+		"zero_result_rate": utils.Percent(stats.ZeroResults, stats.Searches),
+		"clicked_searches": stats.ClickedSearches,
+		"ctr":              utils.Percent(stats.ClickedSearches, stats.Searches),
+	})
+}
+
+// TopQueries 时间窗口内搜索次数最多的搜索语句
+func (h *SearchAnalyticsHandler) TopQueries(c *gin.Context) {
+	h.queries(c, false)
+}
+
+// ZeroResults 时间窗口内没有结果次数最多的搜索语句
+func (h *SearchAnalyticsHandler) ZeroResults(c *gin.Context) {
+	h.queries(c, true)
+}
+
+func (h *SearchAnalyticsHandler) queries(c *gin.Context, zeroOnly bool) {
+	window, since, ok := h.window(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > maxAnalyticsLimit {
+		utils.BadRequest(c, "limit must be between 1 and "+strconv.Itoa(maxAnalyticsLimit))
+		return
+	}
+
+	query := h.db.Model(&models.SearchLog{}).
+		Select("query, COUNT(*) AS searches, COUNT(DISTINCT user_id) AS users, AVG(result_count) AS avg_results, " +
+			"SUM(CASE WHEN clicked_link_id IS NOT NULL THEN 1 ELSE 0 END) AS clicks, MAX(created_at) AS last_searched_at").
+		Where("created_at >= ?", since)
+	if zeroOnly {
+		query = query.Where("result_count = 0")
+	}
+
+	var rows []struct {
+		Query          string
+		Searches       int64
+		Users          int64
+		AvgResults     float64
+		Clicks         int64
+		LastSearchedAt string
+	}
+	query.Group("query").Order("searches DESC, query").Limit(limit).Scan(&rows)
+
+	items := make([]queryStat, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, queryStat{
+			Query:          row.Query,
+			Searches:       row.Searches,
+			Users:          row.Users,
+			AvgResults:     row.AvgResults,
+			Clicks:         row.Clicks,
+			CTR:            utils.Percent(row.Clicks, row.Searches),
+			LastSearchedAt: parseAggregateTime(row.LastSearchedAt),
+		})
+	}
+
+	utils.Success(c, gin.H{
+		"window":  window.String(),
+		"since":   since,
+		"queries": items,
+	})
+}
+
+// window 解析 window 参数（默认 7d）
+func (h *SearchAnalyticsHandler) window(c *gin.Context) (time.Duration, time.Time, bool) {
+	window, err := utils.ParseWindow(c.DefaultQuery("window", "7d"))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return 0, time.Time{}, false
+	}
+	return window, time.Now().Add(-window), true
+}
+
+// aggregateTimeLayouts MAX(created_at) 的返回格式：Postgres 为时间类型（扫描为 RFC 3339），SQLite 为文本
+var aggregateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// parseAggregateTime 解析聚合函数返回的时间，无法解析时返回零值
+func parseAggregateTime(s string) time.Time {
+	for _, layout := range aggregateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
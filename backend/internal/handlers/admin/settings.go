@@ -8,18 +8,20 @@ package admin
 import (
 	"github.com/gin-gonic/gin"
 	"kk-nav/internal/models"
+	"kk-nav/internal/services"
 	"kk-nav/internal/utils"
 	"gorm.io/gorm"
 )
 
 // SettingsHandler 管理后台设置处理器
 type SettingsHandler struct {
-	db *gorm.DB
+	db      *gorm.DB
+	checker *services.LinkChecker
 }
 
 // NewSettingsHandler 创建设置处理器
-func NewSettingsHandler(db *gorm.DB) *SettingsHandler {
-	return &SettingsHandler{db: db}
+func NewSettingsHandler(db *gorm.DB, checker *services.LinkChecker) *SettingsHandler {
+	return &SettingsHandler{db: db, checker: checker}
 }
 
 // Index 获取所有设置
@@ -72,6 +74,9 @@ func (h *SettingsHandler) Update(c *gin.Context) {
 		}
 	}
 
+	// 检测相关设置立即生效
+	h.checker.Reload()
+
 	utils.SuccessWithMessage(c, "Settings updated successfully", nil)
 }
 
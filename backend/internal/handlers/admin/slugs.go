@@ -0,0 +1,131 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package admin
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// SlugsHandler 短链接名处理器
+type SlugsHandler struct {
+	db *gorm.DB
+}
+
+// NewSlugsHandler 创建短链接名处理器
+func NewSlugsHandler(db *gorm.DB) *SlugsHandler {
+	return &SlugsHandler{db: db}
+}
+
+// slugEntry 短链接名或别名及其指向的链接
+type slugEntry struct {
+	Slug      string `json:"slug"`
+	Kind      string `json:"kind"` // slug | alias
+	LinkID    uint   `json:"link_id"`
+	LinkTitle string `json:"link_title"`
+	URL       string `json:"url"`
+}
+
+// Index 所有短链接名和别名，以及被多个链接使用的冲突项
+func (h *SlugsHandler) Index(c *gin.Context) {
+	var entries []slugEntry
+	if err := h.db.Model(&models.Link{}).
+		Select("links.slug AS slug, 'slug' AS kind, links.id AS link_id, links.title AS link_title, links.url AS url").
+		Where("links.slug IS NOT NULL").
+		Scan(&entries).Error; err != nil {
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+
+	var aliases []slugEntry
+	if err := h.db.Table("link_aliases").
+		Select("link_aliases.slug AS slug, 'alias' AS kind, links.id AS link_id, links.title AS link_title, links.url AS url").
+		Joins("JOIN links ON links.id = link_aliases.link_id").
+		Scan(&aliases).Error; err != nil {
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+	entries = append(entries, aliases...)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Slug != entries[j].Slug {
+			return entries[i].Slug < entries[j].Slug
+		}
+		return entries[i].Kind > entries[j].Kind // 同名时短链接名在前，它优先于别名生效
+	})
+
+	// 短链接名和别名分别唯一，但两者之间可能重名（如直接修改了数据库），此时短链接名生效
+	collisions := []gin.H{}
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].Slug == entries[i].Slug {
+			j++
+		}
+		if j-i > 1 {
+			collisions = append(collisions, gin.H{
+				"slug":    entries[i].Slug,
+				"entries": entries[i:j],
+			})
+		}
+		i = j
+	}
+
+	utils.Success(c, gin.H{
+		"slugs":      entries,
+		"total":      len(entries),
+		"collisions": collisions,
+	})
+}
+
+// Check 检查短链接名是否可用，slug 可以用逗号分隔多个；link_id 为正在编辑的链接，其自身的名称不算冲突
+func (h *SlugsHandler) Check(c *gin.Context) {
+	var linkID uint
+	if raw := c.Query("link_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			utils.BadRequest(c, "Invalid link ID")
+			return
+		}
+		linkID = uint(id)
+	}
+
+	var slugs []string
+	for _, slug := range strings.Split(c.Query("slug"), ",") {
+		if slug = models.NormalizeSlug(slug); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		utils.BadRequest(c, "slug is required")
+		return
+	}
+
+	results := make([]gin.H, 0, len(slugs))
+	for _, slug := range slugs {
+		result := gin.H{"slug": slug, "valid": true, "available": false}
+		if err := models.ValidateSlug(slug); err != nil {
+			result["valid"] = false
+			result["error"] = err.Error()
+			results = append(results, result)
+			continue
+		}
+		conflicts, err := models.FindSlugConflicts(h.db, linkID, []string{slug})
+		if err != nil {
+			utils.InternalServerError(c, "Database error")
+			return
+		}
+		result["available"] = len(conflicts) == 0
+		result["conflicts"] = conflicts
+		results = append(results, result)
+	}
+
+	utils.Success(c, gin.H{"results": results})
+}
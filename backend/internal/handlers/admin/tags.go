@@ -10,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
 	"kk-nav/internal/utils"
 	"gorm.io/gorm"
 )
@@ -68,7 +69,7 @@ func (h *TagsHandler) Show(c *gin.Context) {
 	}
 
 	var tag models.Tag
-	if err := h.db.Preload("Links", "status = ?", "active").First(&tag, id).Error; err != nil {
+	if err := h.db.Preload("Links", "status IN ?", models.VisibleLinkStatuses).First(&tag, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Tag not found")
 			return
@@ -139,6 +140,7 @@ func (h *TagsHandler) Update(c *gin.Context) {
 		utils.InternalServerError(c, "Failed to update tag")
 		return
 	}
+	search.ReindexTag(h.db, tag.ID)
 
 	utils.SuccessWithMessage(c, "Tag updated successfully", tag)
 }
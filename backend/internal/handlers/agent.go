@@ -0,0 +1,132 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/middleware"
+	"kk-nav/internal/models"
+	"kk-nav/internal/services"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxAgentResults 单次上报的最大结果数
+const maxAgentResults = 5000
+
+// AgentHandler 检测代理接口处理器（代理拉取所在区域的链接并上报检测结果）
+type AgentHandler struct {
+	db      *gorm.DB
+	checker *services.LinkChecker
+}
+
+// NewAgentHandler 创建检测代理接口处理器
+func NewAgentHandler(db *gorm.DB, checker *services.LinkChecker) *AgentHandler {
+	return &AgentHandler{db: db, checker: checker}
+}
+
+// Heartbeat 心跳，记录代理版本和主机名，返回检测调度参数
+func (h *AgentHandler) Heartbeat(c *gin.Context) {
+	agent, _ := middleware.GetAgent(c)
+
+	var req struct {
+		Version  string `json:"version" binding:"max=50"`
+		Hostname string `json:"hostname" binding:"max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	agent.Version = req.Version
+	agent.Hostname = req.Hostname
+	if err := h.db.Model(agent).Select("version", "hostname").Updates(agent).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update agent")
+		return
+	}
+
+	utils.Success(c, h.schedule(agent))
+}
+
+// Links 代理所在区域中参与检测的链接
+func (h *AgentHandler) Links(c *gin.Context) {
+	agent, _ := middleware.GetAgent(c)
+
+	var links []models.Link
+	if err := h.db.Where("zone = ? AND status IN ?", agent.Zone, models.CheckedLinkStatuses).
+		Scopes(models.PreloadEndpoints).Order("id").Find(&links).Error; err != nil {
+		utils.InternalServerError(c, "Failed to fetch links")
+		return
+	}
+
+	// 跳过处于 skip 维护窗口的链接
+	maintenance, err := models.ActiveMaintenance(h.db, time.Now())
+	if err != nil {
+		utils.InternalServerError(c, "Failed to load maintenance windows")
+		return
+	}
+	checked := links[:0]
+	for _, link := range links {
+		if w := maintenance.For(link.ID); w == nil || w.Mode != models.MaintenanceSkip {
+			checked = append(checked, link)
+		}
+	}
+	links = checked
+
+	data := h.schedule(agent)
+	data["links"] = services.NewAgentLinks(links)
+	utils.Success(c, data)
+}
+
+// Results 上报检测结果
+func (h *AgentHandler) Results(c *gin.Context) {
+	agent, _ := middleware.GetAgent(c)
+
+	var req struct {
+		Results []services.AgentResult `json:"results" binding:"dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if len(req.Results) > maxAgentResults {
+		utils.BadRequest(c, "Too many results in one report")
+		return
+	}
+
+	applied, err := h.checker.ApplyAgentResults(agent, req.Results)
+	if errors.Is(err, services.ErrCheckingDisabled) {
+		utils.ErrorWithStatus(c, http.StatusConflict, 409, err.Error())
+		return
+	}
+	if err != nil {
+		utils.InternalServerError(c, "Failed to save results")
+		return
+	}
+
+	now := time.Now()
+	h.db.Model(agent).UpdateColumn("last_report_at", now)
+
+	utils.Success(c, gin.H{
+		"accepted": applied,
+		"ignored":  len(req.Results) - applied,
+	})
+}
+
+// schedule 代理使用的检测调度参数（与中心检测服务的设置一致）
+func (h *AgentHandler) schedule(agent *models.Agent) gin.H {
+	status := h.checker.Status()
+	return gin.H{
+		"agent":            agent,
+		"zone":             agent.Zone,
+		"enabled":          status.Enabled && !status.Paused,
+		"interval_seconds": int64(status.IntervalHours * 3600),
+	}
+}
@@ -164,11 +164,58 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	}
 
 	utils.Success(c, gin.H{
-		"id":       user.ID,
-		"email":    user.Email,
-		"username": user.Username,
-		"role":     user.Role,
-		"active":   user.Active,
+		"id":                    user.ID,
+		"email":                 user.Email,
+		"username":              user.Username,
+		"role":                  user.Role,
+		"active":                user.Active,
+		"preferred_environment": user.PreferredEnvironment,
+	})
+}
+
+// PreferencesRequest 用户偏好设置请求
+type PreferencesRequest struct {
+	PreferredEnvironment *string `json:"preferred_environment"` // 空字符串表示使用链接的默认环境
+}
+
+// UpdatePreferences 更新当前用户的偏好设置
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		utils.NotFound(c, "User not found")
+		return
+	}
+
+	if req.PreferredEnvironment != nil {
+		env := models.NormalizeEnvironment(*req.PreferredEnvironment)
+		if env != "" {
+			if err := models.ValidateEnvironment(env); err != nil {
+				utils.BadRequest(c, err.Error())
+				return
+			}
+		}
+		user.PreferredEnvironment = env
+	}
+
+	if err := h.db.Model(&user).Update("preferred_environment", user.PreferredEnvironment).Error; err != nil {
+		utils.InternalServerError(c, "Failed to update preferences")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "Preferences updated successfully", gin.H{
+		"preferred_environment": user.PreferredEnvironment,
 	})
 }
 
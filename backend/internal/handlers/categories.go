@@ -57,7 +57,7 @@ func (h *CategoriesHandler) Show(c *gin.Context) {
 	}
 
 	var category models.Category
-	if err := h.db.Preload("Links", "status = ?", "active").First(&category, id).Error; err != nil {
+	if err := h.db.Preload("Links", "status IN ?", models.VisibleLinkStatuses).First(&category, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Category not found")
 			return
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// GoLinksHandler 短链接跳转处理器
+type GoLinksHandler struct {
+	db *gorm.DB
+}
+
+// NewGoLinksHandler 创建短链接跳转处理器
+func NewGoLinksHandler(db *gorm.DB) *GoLinksHandler {
+	return &GoLinksHandler{db: db}
+}
+
+// Redirect 短链接跳转：/go/<slug> 跳转到短链接名或别名对应的链接并记录点击，
+// /go/<slug>/<路径> 把路径追加到目标地址之后（如 /go/jira/OPS-123）。
+// env 和 var-<name> 参数与点击链接相同；短链接不存在或链接不在前台展示时跳转到首页搜索。
+func (h *GoLinksHandler) Redirect(c *gin.Context) {
+	slug, suffix, _ := strings.Cut(strings.TrimPrefix(c.Param("path"), "/"), "/")
+	if slug == "" {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	// 停用等不在前台展示的链接与不存在的短链接一样跳转到搜索
+	link, err := models.FindLinkBySlug(h.db, slug)
+	if err == nil {
+		err = h.db.Scopes(models.PreloadEndpoints).Where("status IN ?", models.VisibleLinkStatuses).First(link, link.ID).Error
+	}
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			utils.InternalServerError(c, "Database error")
+			return
+		}
+		q := strings.TrimSpace(slug + " " + strings.ReplaceAll(suffix, "/", " "))
+		c.Redirect(http.StatusFound, "/?search="+url.QueryEscape(q))
+		return
+	}
+
+	target, environment, ok := resolveEndpoint(h.db, c, link, c.Query("env"))
+	if !ok {
+		utils.BadRequest(c, "Unknown environment: "+c.Query("env"))
+		return
+	}
+	target, err = link.ExpandURL(target, urlVariables(c))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+	if suffix != "" {
+		if target, err = appendPath(target, suffix); err != nil {
+			utils.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	if err := recordClick(h.db, c, link, environment, 0); err != nil {
+		utils.InternalServerError(c, "Failed to increment click count")
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// appendPath 把路径追加到目标地址的路径之后，保留目标地址的查询参数和片段；不允许 . 和 .. 路径段
+func appendPath(target, suffix string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	segments := strings.Split(suffix, "/")
+	for _, s := range segments {
+		if s == "." || s == ".." {
+			return "", fmt.Errorf("invalid path %q", suffix)
+		}
+	}
+	return u.JoinPath(segments...).String(), nil
+}
@@ -6,10 +6,13 @@
 package handlers
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"kk-nav/internal/middleware"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
 	"kk-nav/internal/utils"
 	"gorm.io/gorm"
 )
@@ -32,12 +35,22 @@ func (h *HomeHandler) Index(c *gin.Context) {
 
 	// 获取链接
 	var links []models.Link
-	query := h.db.Where("status = ?", "active").Preload("Category").Preload("Tags")
+	query := h.db.Where("status IN ?", models.VisibleLinkStatuses).Preload("Category").Preload("Tags").Scopes(models.PreloadEndpoints)
 
 	// 搜索
-	if search := c.Query("search"); search != "" {
-		query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	userID, _ := middleware.GetUserID(c)
+	result, err := search.Query(h.db, c.Query("search"), userID)
+	if err != nil {
+		var queryErr *search.QueryError
+		if errors.As(err, &queryErr) {
+			utils.BadRequest(c, queryErr.Error())
+			return
+		}
+		utils.InternalServerError(c, "Search failed")
+		return
+	}
+	if result != nil {
+		query = result.Filter(query)
 	}
 
 	// 标签筛选
@@ -52,13 +65,19 @@ func (h *HomeHandler) Index(c *gin.Context) {
 		query = query.Where("category_id = ?", categoryID)
 	}
 
+	if result != nil {
+		query = result.Order(query)
+	}
 	query.Find(&links)
+	models.MarkMaintenance(h.db, links)
+	result.Highlight(links)
+	searchID := search.LogQuery(h.db, c.Query("search"), userID, c.ClientIP(), search.SourceHome, int64(len(links)))
 
 	// 获取热门标签
 	var tags []models.Tag
 	h.db.Joins("JOIN link_tags ON link_tags.tag_id = tags.id").
 		Joins("JOIN links ON links.id = link_tags.link_id").
-		Where("links.status = ?", "active").
+		Where("links.status IN ?", models.VisibleLinkStatuses).
 		Group("tags.id").
 		Order("COUNT(links.id) DESC").
 		Limit(20).
@@ -71,7 +90,7 @@ func (h *HomeHandler) Index(c *gin.Context) {
 		TotalClicks     int64
 		TodayClicks     int64
 	}
-	h.db.Model(&models.Link{}).Where("status = ?", "active").Count(&stats.TotalLinks)
+	h.db.Model(&models.Link{}).Where("status IN ?", models.VisibleLinkStatuses).Count(&stats.TotalLinks)
 	h.db.Model(&models.Category{}).Where("active = ?", true).Count(&stats.TotalCategories)
 	h.db.Model(&models.ClickLog{}).Count(&stats.TotalClicks)
 
@@ -79,11 +98,15 @@ func (h *HomeHandler) Index(c *gin.Context) {
 	todayStart := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, time.Now().Location())
 	h.db.Model(&models.ClickLog{}).Where("created_at >= ?", todayStart).Count(&stats.TodayClicks)
 
-	utils.Success(c, gin.H{
+	response := gin.H{
 		"categories": categories,
 		"links":      links,
 		"tags":       tags,
 		"stats":      stats,
-	})
+	}
+	if searchID != 0 {
+		response["search_id"] = searchID
+	}
+	utils.Success(c, response)
 }
 
@@ -6,11 +6,18 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"kk-nav/internal/middleware"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
+	"kk-nav/internal/services"
 	"kk-nav/internal/utils"
 	"gorm.io/gorm"
 )
@@ -28,12 +35,22 @@ func NewLinksHandler(db *gorm.DB) *LinksHandler {
 // Index 链接列表
 func (h *LinksHandler) Index(c *gin.Context) {
 	var links []models.Link
-	query := h.db.Where("status = ?", "active").Preload("Category").Preload("Tags")
+	query := h.db.Where("status IN ?", models.VisibleLinkStatuses).Preload("Category").Preload("Tags").Scopes(models.PreloadEndpoints)
 
 	// 搜索
-	if search := c.Query("search"); search != "" {
-		query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	userID, _ := middleware.GetUserID(c)
+	result, err := search.Query(h.db, c.Query("search"), userID)
+	if err != nil {
+		var queryErr *search.QueryError
+		if errors.As(err, &queryErr) {
+			utils.BadRequest(c, queryErr.Error())
+			return
+		}
+		utils.InternalServerError(c, "Search failed")
+		return
+	}
+	if result != nil {
+		query = result.Filter(query)
 	}
 
 	// 分类筛选
@@ -55,9 +72,16 @@ func (h *LinksHandler) Index(c *gin.Context) {
 
 	var total int64
 	query.Model(&models.Link{}).Count(&total)
+	if result != nil {
+		query = result.Order(query)
+	}
 	query.Order("sort_order").Offset(offset).Limit(pageSize).Find(&links)
 
-	utils.Success(c, gin.H{
+	// 维护提示和搜索命中片段
+	models.MarkMaintenance(h.db, links)
+	result.Highlight(links)
+
+	response := gin.H{
 		"links": links,
 		"pagination": gin.H{
 			"page":       page,
@@ -65,7 +89,14 @@ func (h *LinksHandler) Index(c *gin.Context) {
 			"total":      total,
 			"total_page": (int(total) + pageSize - 1) / pageSize,
 		},
-	})
+	}
+	// 搜索记录（翻页不重复记录），点击链接时带上 search_id 以统计点击率
+	if page == 1 {
+		if searchID := search.LogQuery(h.db, c.Query("search"), userID, c.ClientIP(), search.SourceAPI, total); searchID != 0 {
+			response["search_id"] = searchID
+		}
+	}
+	utils.Success(c, response)
 }
 
 // Show 链接详情
@@ -77,7 +108,7 @@ func (h *LinksHandler) Show(c *gin.Context) {
 	}
 
 	var link models.Link
-	if err := h.db.Preload("Category").Preload("Tags").First(&link, id).Error; err != nil {
+	if err := h.db.Preload("Category").Preload("Tags").Scopes(models.PreloadEndpoints).First(&link, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Link not found")
 			return
@@ -88,18 +119,75 @@ func (h *LinksHandler) Show(c *gin.Context) {
 
 	// 获取相关链接
 	var relatedLinks []models.Link
-	h.db.Where("category_id = ? AND id != ? AND status = ?", link.CategoryID, link.ID, "active").
+	h.db.Where("category_id = ? AND id != ? AND status IN ?", link.CategoryID, link.ID, models.VisibleLinkStatuses).
 		Order("click_count DESC").
 		Limit(6).
 		Find(&relatedLinks)
 
+	// 维护提示
+	marked := append([]models.Link{link}, relatedLinks...)
+	models.MarkMaintenance(h.db, marked)
+	link, relatedLinks = marked[0], marked[1:]
+
 	utils.Success(c, gin.H{
-		"link":         link,
+		"link":          link,
 		"related_links": relatedLinks,
 	})
 }
 
-// Click 记录点击
+// Icon 链接图标：返回缓存的 PNG 图标，没有图标时返回按标题生成的字母图标（SVG）。
+// v 参数与图标的 icon_hash 一致时可以长期缓存，图标变化后前端使用新的 v 参数。
+func (h *LinksHandler) Icon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "Invalid link ID")
+		return
+	}
+
+	var icon models.LinkIcon
+	if err := h.db.Select("link_id, hash, data").Where("link_id = ? AND hash <> ?", id, "").First(&icon).Error; err == nil && len(icon.Data) > 0 {
+		if v := c.Query("v"); v != "" && v == icon.Hash {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			c.Header("Cache-Control", "public, max-age=86400")
+		}
+		serveIcon(c, `"`+icon.Hash+`"`, "image/png", icon.Data)
+		return
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+
+	var link models.Link
+	if err := h.db.Select("id, title").First(&link, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFound(c, "Link not found")
+			return
+		}
+		utils.InternalServerError(c, "Database error")
+		return
+	}
+	data := services.LetterIcon(link.Title)
+	// 字母图标只缓存较短时间，以便获取到图标后尽快生效
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	serveIcon(c, fmt.Sprintf(`"letter-%x"`, sha256.Sum256(data)), "image/svg+xml", data)
+}
+
+// serveIcon 写入图标，If-None-Match 与 ETag 一致时返回 304
+func serveIcon(c *gin.Context, etag, contentType string, data []byte) {
+	c.Header("ETag", etag)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Click 记录点击并跳转，env 参数指定环境，未指定时使用用户偏好的环境或默认环境；
+// 模板链接的变量值通过 var-<name> 参数传入，未传入的使用默认值。
+// 请求优先接受 JSON 时（前端 XHR）返回跳转地址而不是重定向。
 func (h *LinksHandler) Click(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -108,7 +196,7 @@ func (h *LinksHandler) Click(c *gin.Context) {
 	}
 
 	var link models.Link
-	if err := h.db.First(&link, id).Error; err != nil {
+	if err := h.db.Scopes(models.PreloadEndpoints).First(&link, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Link not found")
 			return
@@ -117,35 +205,88 @@ func (h *LinksHandler) Click(c *gin.Context) {
 		return
 	}
 
-	// 增加点击数
-	if err := link.IncrementClickCount(); err != nil {
+	target, environment, ok := resolveEndpoint(h.db, c, &link, c.Query("env"))
+	if !ok {
+		utils.BadRequest(c, "Unknown environment: "+c.Query("env"))
+		return
+	}
+	target, err = link.ExpandURL(target, urlVariables(c))
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	// 搜索点击率统计，只统计带 search_id 的点击
+	searchID, _ := strconv.ParseUint(c.Query("search_id"), 10, 32)
+	if err := recordClick(h.db, c, &link, environment, uint(searchID)); err != nil {
 		utils.InternalServerError(c, "Failed to increment click count")
 		return
 	}
 
-	// 记录点击日志
-	var userID *uint
-	if uid, exists := c.Get("user_id"); exists {
-		if id, ok := uid.(uint); ok {
-			userID = &id
+	if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		utils.Success(c, gin.H{
+			"url":         target,
+			"environment": environment,
+		})
+		return
+	}
+
+	// 重定向到链接
+	c.Redirect(http.StatusFound, target)
+}
+
+// urlVariables 从 var-<name> 查询参数读取 URL 模板变量值
+func urlVariables(c *gin.Context) map[string]string {
+	var values map[string]string
+	for key, v := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, "var-")
+		if !ok || len(v) == 0 {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
 		}
+		values[name] = v[0]
 	}
+	return values
+}
+
+// resolveEndpoint 选择跳转的环境地址（链接需预加载 Endpoints），登录用户使用其偏好的环境
+func resolveEndpoint(db *gorm.DB, c *gin.Context, link *models.Link, env string) (target, environment string, ok bool) {
+	var preferred string
+	if userID, exists := middleware.GetUserID(c); exists && len(link.Endpoints) > 0 {
+		db.Model(&models.User{}).Where("id = ?", userID).Pluck("preferred_environment", &preferred)
+	}
+	return link.ResolveEndpoint(env, preferred)
+}
 
+// recordClick 增加链接点击数并记录点击日志、补全热度和搜索点击（searchID 为 0 时按访客归属）
+func recordClick(db *gorm.DB, c *gin.Context, link *models.Link, environment string, searchID uint) error {
+	// 增加点击数
+	if err := link.IncrementClickCount(); err != nil {
+		return err
+	}
+
+	// 记录点击日志
+	userID, _ := middleware.GetUserID(c)
 	clickLog := models.ClickLog{
-		LinkID:    link.ID,
-		UserID:    userID,
-		IPAddress: c.ClientIP(),
-		UserAgent: c.GetHeader("User-Agent"),
-		Referer:   c.GetHeader("Referer"),
+		LinkID:      link.ID,
+		Environment: environment,
+		IPAddress:   c.ClientIP(),
+		UserAgent:   c.GetHeader("User-Agent"),
+		Referer:     c.GetHeader("Referer"),
+	}
+	if userID != 0 {
+		clickLog.UserID = &userID
 	}
 
-	if err := h.db.Create(&clickLog).Error; err != nil {
+	if err := db.Create(&clickLog).Error; err != nil {
 		// 日志记录失败不影响主流程
 		_ = err
 	}
-
-	// 重定向到链接
-	c.Redirect(http.StatusFound, link.URL)
+	search.RecordClick(userID, link.ID)
+	search.LogClick(db, searchID, link.ID)
+	return nil
 }
 
 // Favorite 添加收藏
@@ -227,12 +368,13 @@ func (h *LinksHandler) Favorites(c *gin.Context) {
 	var favorites []models.Favorite
 	h.db.Where("user_id = ?", userID).
 		Preload("Link").Preload("Link.Category").Preload("Link.Tags").
+		Preload("Link.Endpoints", func(tx *gorm.DB) *gorm.DB { return tx.Order("sort_order, id") }).
 		Order("created_at DESC").
 		Find(&favorites)
 
 	links := make([]models.Link, 0, len(favorites))
 	for _, fav := range favorites {
-		if fav.Link.Status == "active" {
+		if fav.Link.IsVisible() {
 			links = append(links, fav.Link)
 		}
 	}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/config"
+	"kk-nav/internal/middleware"
+	"kk-nav/internal/models"
+	"kk-nav/internal/search"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// SearchHandler 搜索处理器
+type SearchHandler struct {
+	db *gorm.DB
+}
+
+// NewSearchHandler 创建搜索处理器
+func NewSearchHandler(db *gorm.DB) *SearchHandler {
+	return &SearchHandler{db: db}
+}
+
+// Suggest 搜索框自动补全，从内存索引返回匹配前缀的链接、分类和标签；
+// 登录用户自己常点的链接排在前面，q 为空时返回常用链接
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(search.DefaultSuggestions)))
+	if err != nil || limit < 1 || limit > search.MaxSuggestions {
+		utils.BadRequest(c, "limit must be between 1 and "+strconv.Itoa(search.MaxSuggestions))
+		return
+	}
+
+	q := c.Query("q")
+	userID, _ := middleware.GetUserID(c)
+	utils.Success(c, gin.H{
+		"query":       q,
+		"suggestions": search.Suggest(h.db, q, userID, limit),
+	})
+}
+
+// openSearchShortNameMax OpenSearch ShortName 的最大长度
+const openSearchShortNameMax = 16
+
+// openSearchDescription OpenSearch 描述文档
+type openSearchDescription struct {
+	XMLName       xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName     string          `xml:"ShortName"`
+	Description   string          `xml:"Description"`
+	InputEncoding string          `xml:"InputEncoding"`
+	URLs          []openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Method   string `xml:"method,attr,omitempty"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Template string `xml:"template,attr"`
+}
+
+// OpenSearch 浏览器搜索引擎描述文档，名称取自 site_name 设置
+func (h *SearchHandler) OpenSearch(c *gin.Context) {
+	base, fromRequest := baseURL(c)
+	name := []rune(models.GetSetting("site_name"))
+	if len(name) > openSearchShortNameMax {
+		name = name[:openSearchShortNameMax]
+	}
+
+	doc := openSearchDescription{
+		ShortName:     string(name),
+		Description:   models.GetSetting("site_description"),
+		InputEncoding: "UTF-8",
+		URLs: []openSearchURL{
+			{Type: "text/html", Method: "get", Template: base + "/api/v1/search/go?q={searchTerms}"},
+			{Type: "application/x-suggestions+json", Method: "get", Template: base + "/api/v1/search/opensearch?q={searchTerms}"},
+			{Type: "application/opensearchdescription+xml", Rel: "self", Template: base + "/api/v1/opensearch.xml"},
+		},
+	}
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		utils.InternalServerError(c, "Failed to render OpenSearch description")
+		return
+	}
+	if fromRequest {
+		// 地址取自请求头时不能放进共享缓存，否则伪造的请求头会影响其他用户
+		c.Header("Cache-Control", "private, max-age=3600")
+		c.Header("Vary", "Host, X-Forwarded-Host, X-Forwarded-Proto")
+	} else {
+		c.Header("Cache-Control", "public, max-age=3600")
+	}
+	c.Data(http.StatusOK, "application/opensearchdescription+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// OpenSearchSuggest 浏览器地址栏补全，返回 OpenSearch 建议格式：[输入, [补全], [说明], [地址]]
+func (h *SearchHandler) OpenSearchSuggest(c *gin.Context) {
+	q := c.Query("q")
+	userID, _ := middleware.GetUserID(c)
+
+	items := search.Suggest(h.db, q, userID, search.DefaultSuggestions)
+	completions := make([]string, 0, len(items))
+	descriptions := make([]string, 0, len(items))
+	urls := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Type != search.SuggestionLink {
+			continue
+		}
+		completions = append(completions, item.Title)
+		descriptions = append(descriptions, item.Category)
+		urls = append(urls, item.URL)
+	}
+
+	c.Header("Content-Type", "application/x-suggestions+json; charset=utf-8")
+	c.JSON(http.StatusOK, []interface{}{q, completions, descriptions, urls})
+}
+
+// Go 手气不错：直接跳转到唯一最匹配的链接并记录点击，没有足够明确的结果时跳转到首页搜索
+func (h *SearchHandler) Go(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+	fallback := "/?search=" + url.QueryEscape(q)
+
+	userID, _ := middleware.GetUserID(c)
+	linkID, err := search.Lucky(h.db, q, userID)
+	if err != nil || linkID == 0 {
+		c.Redirect(http.StatusFound, fallback)
+		return
+	}
+	var link models.Link
+	if err := h.db.Scopes(models.PreloadEndpoints).First(&link, linkID).Error; err != nil {
+		c.Redirect(http.StatusFound, fallback)
+		return
+	}
+	target, environment, _ := resolveEndpoint(h.db, c, &link, "")
+	target = link.DefaultURLFor(target)
+
+	searchID := search.LogQuery(h.db, q, userID, c.ClientIP(), search.SourceOpenSearch, 1)
+	if err := recordClick(h.db, c, &link, environment, searchID); err != nil {
+		utils.InternalServerError(c, "Failed to increment click count")
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
+
+// baseURL 对外访问地址：优先使用配置的 APP_PUBLIC_URL；未配置时取自请求（包括反向代理传入的协议和主机名），
+// 此时 fromRequest 为 true，响应不能被共享缓存
+func baseURL(c *gin.Context) (base string, fromRequest bool) {
+	if public := config.Get().App.PublicURL; public != "" {
+		return public, false
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := strings.TrimSpace(strings.Split(c.GetHeader("X-Forwarded-Proto"), ",")[0]); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host, true
+}
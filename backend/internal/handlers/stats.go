@@ -37,7 +37,7 @@ func (h *StatsHandler) Index(c *gin.Context) {
 	}
 
 	// 基础统计
-	h.db.Model(&models.Link{}).Where("status = ?", "active").Count(&stats.TotalLinks)
+	h.db.Model(&models.Link{}).Where("status IN ?", models.VisibleLinkStatuses).Count(&stats.TotalLinks)
 	h.db.Model(&models.Category{}).Where("active = ?", true).Count(&stats.TotalCategories)
 	h.db.Model(&models.Tag{}).Count(&stats.TotalTags)
 	h.db.Model(&models.ClickLog{}).Count(&stats.TotalClicks)
@@ -58,7 +58,7 @@ func (h *StatsHandler) Index(c *gin.Context) {
 		ClickCount int    `json:"click_count"`
 	}
 	h.db.Model(&models.Link{}).
-		Where("status = ?", "active").
+		Where("status IN ?", models.VisibleLinkStatuses).
 		Order("click_count DESC").
 		Limit(10).
 		Select("title, click_count").
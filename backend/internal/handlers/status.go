@@ -0,0 +1,332 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+	"gorm.io/gorm"
+)
+
+// statusWindows 状态页支持的可用率统计窗口（固定取值，避免缓存条目无限增长）
+var statusWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+// 状态页的链接健康状态
+const (
+	healthUp          = "up"
+	healthDegraded    = "degraded"
+	healthDown        = "down"
+	healthMaintenance = "maintenance"
+)
+
+// 分类和整体的汇总状态
+const (
+	rollupOperational   = "operational"
+	rollupDegraded      = "degraded"
+	rollupPartialOutage = "partial_outage"
+	rollupMajorOutage   = "major_outage"
+	rollupMaintenance   = "maintenance"
+)
+
+// StatusHandler 公开状态页处理器
+type StatusHandler struct {
+	db *gorm.DB
+
+	mu    sync.Mutex
+	cache map[string]*statusPage
+}
+
+// NewStatusHandler 创建状态页处理器
+func NewStatusHandler(db *gorm.DB) *StatusHandler {
+	return &StatusHandler{db: db, cache: make(map[string]*statusPage)}
+}
+
+// statusPage 缓存的状态页响应
+type statusPage struct {
+	body      []byte
+	etag      string
+	expiresAt time.Time
+}
+
+// statusLink 状态页中的链接
+type statusLink struct {
+	ID                 uint       `json:"id"`
+	Title              string     `json:"title"`
+	URL                string     `json:"url"`
+	Status             string     `json:"status"` // up | degraded | down | maintenance
+	MaintenanceMessage string     `json:"maintenance_message,omitempty"`
+	LastCheckedAt      *time.Time `json:"last_checked_at"`
+	Uptime             *float64   `json:"uptime"` // 统计窗口内的可用率（%），没有检测记录时为 null
+	AvgLatencyMs       *float64   `json:"avg_latency_ms"`
+}
+
+// statusRollup 分类或整体的汇总
+type statusRollup struct {
+	Status      string   `json:"status"` // operational | degraded | partial_outage | major_outage | maintenance
+	Total       int      `json:"total"`
+	Up          int      `json:"up"`
+	Degraded    int      `json:"degraded"`
+	Down        int      `json:"down"`
+	Maintenance int      `json:"maintenance"`
+	Uptime      *float64 `json:"uptime"`
+
+	checks, success int64
+}
+
+// statusCategory 状态页中的分类
+type statusCategory struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Icon  string `json:"icon"`
+	Color string `json:"color"`
+	statusRollup
+	Links []statusLink `json:"links"`
+}
+
+// add 累计一个链接
+func (r *statusRollup) add(link *statusLink, checks, success int64) {
+	r.Total++
+	switch link.Status {
+	case healthUp:
+		r.Up++
+	case healthDegraded:
+		r.Degraded++
+	case healthDown:
+		r.Down++
+	case healthMaintenance:
+		r.Maintenance++
+	}
+	r.checks += checks
+	r.success += success
+}
+
+// finish 计算汇总状态和可用率
+func (r *statusRollup) finish() {
+	if r.checks > 0 {
+		uptime := utils.Percent(r.success, r.checks)
+		r.Uptime = &uptime
+	}
+
+	monitored := r.Total - r.Maintenance
+	switch {
+	case r.Down == 0 && r.Degraded == 0 && r.Maintenance > 0 && monitored == 0:
+		r.Status = rollupMaintenance
+	case r.Down == 0 && r.Degraded == 0:
+		r.Status = rollupOperational
+	case r.Down == 0:
+		r.Status = rollupDegraded
+	case r.Down*2 > monitored:
+		r.Status = rollupMajorOutage
+	default:
+		r.Status = rollupPartialOutage
+	}
+}
+
+// Index 公开状态页：按分类汇总链接健康状态、最近检测时间和可用率（?window=24h|7d|30d|90d，默认 7d）
+func (h *StatusHandler) Index(c *gin.Context) {
+	if !models.GetSettingBool("enable_status_page", true) {
+		utils.NotFound(c, "Status page is disabled")
+		return
+	}
+
+	key := c.DefaultQuery("window", "7d")
+	window, ok := statusWindows[key]
+	if !ok {
+		utils.BadRequest(c, "window must be one of 24h, 7d, 30d, 90d")
+		return
+	}
+
+	page, err := h.page(key, window)
+	if err != nil {
+		utils.InternalServerError(c, "Failed to build status page")
+		return
+	}
+
+	ttl := time.Until(page.expiresAt)
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ttl.Seconds())))
+	c.Header("ETag", page.etag)
+	if match := c.GetHeader("If-None-Match"); match != "" && match == page.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", page.body)
+}
+
+// page 返回缓存的状态页，过期时重新生成
+func (h *StatusHandler) page(key string, window time.Duration) (*statusPage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if page, ok := h.cache[key]; ok && now.Before(page.expiresAt) {
+		return page, nil
+	}
+
+	data, err := h.build(now, window)
+	if err != nil {
+		return nil, err
+	}
+
+	// ETag 只取决于状态数据，不包含生成时间
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+
+	data["window"] = key
+	data["generated_at"] = now
+	body, err := json.Marshal(utils.Response{Code: 0, Message: "success", Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(models.GetSettingInt("status_page_cache_seconds", 30)) * time.Second
+	page := &statusPage{
+		body:      body,
+		etag:      `"` + hex.EncodeToString(sum[:16]) + `"`,
+		expiresAt: now.Add(ttl),
+	}
+	h.cache[key] = page
+	return page, nil
+}
+
+// build 查询状态页数据：启用且未在状态页隐藏的分类中参与检测的链接
+func (h *StatusHandler) build(now time.Time, window time.Duration) (gin.H, error) {
+	var categories []models.Category
+	if err := h.db.Where("active = ? AND hidden_on_status_page = ?", true, false).
+		Order("sort_order").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	categoryIDs := make([]uint, 0, len(categories))
+	for _, category := range categories {
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+
+	var links []models.Link
+	if len(categoryIDs) > 0 {
+		if err := h.db.Select("id, title, url, category_id, status, last_checked_at").
+			Where("category_id IN ? AND status IN ?", categoryIDs, models.CheckedLinkStatuses).
+			Order("sort_order").Find(&links).Error; err != nil {
+			return nil, err
+		}
+	}
+	linkIDs := make([]uint, 0, len(links))
+	for _, link := range links {
+		linkIDs = append(linkIDs, link.ID)
+	}
+
+	// 统计窗口内每个链接的检测次数、成功次数和平均延迟
+	type linkStats struct {
+		LinkID       uint
+		Total        int64
+		Success      int64
+		AvgLatencyMs float64
+	}
+	var rows []linkStats
+	if len(linkIDs) > 0 {
+		if err := h.db.Model(&models.LinkCheckResult{}).
+			Select("link_id, COUNT(*) AS total, SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END) AS success, AVG(latency_ms) AS avg_latency_ms").
+			Where("link_id IN ? AND checked_at >= ?", linkIDs, now.Add(-window)).
+			Group("link_id").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	stats := make(map[uint]linkStats, len(rows))
+	for _, row := range rows {
+		stats[row.LinkID] = row
+	}
+
+	maintenance, err := models.ActiveMaintenance(h.db, now)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[uint][]models.Link)
+	for _, link := range links {
+		byCategory[link.CategoryID] = append(byCategory[link.CategoryID], link)
+	}
+
+	overall := statusRollup{}
+	items := make([]statusCategory, 0, len(categories))
+	for _, category := range categories {
+		categoryLinks := byCategory[category.ID]
+		if len(categoryLinks) == 0 {
+			continue
+		}
+
+		item := statusCategory{
+			ID:    category.ID,
+			Name:  category.Name,
+			Icon:  category.Icon,
+			Color: category.Color,
+			Links: make([]statusLink, 0, len(categoryLinks)),
+		}
+		for _, link := range categoryLinks {
+			sl := statusLink{
+				ID:            link.ID,
+				Title:         link.Title,
+				URL:           link.URL,
+				Status:        healthOf(link.Status),
+				LastCheckedAt: link.LastCheckedAt,
+			}
+			if w := maintenance.For(link.ID); w != nil {
+				sl.Status = healthMaintenance
+				sl.MaintenanceMessage = w.Message
+			}
+
+			s := stats[link.ID]
+			if s.Total > 0 {
+				uptime := utils.Percent(s.Success, s.Total)
+				latency := s.AvgLatencyMs
+				sl.Uptime = &uptime
+				sl.AvgLatencyMs = &latency
+			}
+
+			item.add(&sl, s.Total, s.Success)
+			overall.add(&sl, s.Total, s.Success)
+			item.Links = append(item.Links, sl)
+		}
+		item.finish()
+		items = append(items, item)
+	}
+	overall.finish()
+
+	return gin.H{
+		"overall":    overall,
+		"categories": items,
+	}, nil
+}
+
+// healthOf 链接状态转换为状态页的健康状态
+func healthOf(status string) string {
+	switch status {
+	case models.LinkStatusActive:
+		return healthUp
+	case models.LinkStatusDegraded:
+		return healthDegraded
+	default:
+		return healthDown
+	}
+}
@@ -31,7 +31,7 @@ func (h *TagsHandler) Index(c *gin.Context) {
 	// 获取热门标签（按链接数排序）
 	query := h.db.Joins("JOIN link_tags ON link_tags.tag_id = tags.id").
 		Joins("JOIN links ON links.id = link_tags.link_id").
-		Where("links.status = ?", "active").
+		Where("links.status IN ?", models.VisibleLinkStatuses).
 		Group("tags.id").
 		Order("COUNT(links.id) DESC")
 
@@ -63,7 +63,7 @@ func (h *TagsHandler) Show(c *gin.Context) {
 	}
 
 	var tag models.Tag
-	if err := h.db.Preload("Links", "status = ?", "active").First(&tag, id).Error; err != nil {
+	if err := h.db.Preload("Links", "status IN ?", models.VisibleLinkStatuses).First(&tag, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			utils.NotFound(c, "Tag not found")
 			return
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"kk-nav/internal/models"
+	"kk-nav/internal/search"
 	"gorm.io/gorm"
 )
 
@@ -38,13 +39,20 @@ func (h *WebHandler) Home(c *gin.Context) {
 
 	// 获取链接
 	var links []models.Link
-	query := h.db.Where("status = ?", "active").Preload("Category").Preload("Tags")
+	query := h.db.Where("status IN ?", models.VisibleLinkStatuses).Preload("Category").Preload("Tags").Scopes(models.PreloadEndpoints)
 
-	// 搜索
-	search := c.Query("search")
-	if search != "" {
-		query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?",
-			"%"+search+"%", "%"+search+"%", "%"+search+"%")
+	// 搜索（搜索失败时不显示结果）
+	keyword := c.Query("search")
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+	result, err := search.Query(h.db, keyword, userID)
+	if err != nil {
+		result = &search.Result{Query: keyword}
+	}
+	if result != nil {
+		query = result.Filter(query)
 	}
 
 	// 标签筛选
@@ -60,7 +68,11 @@ func (h *WebHandler) Home(c *gin.Context) {
 		query = query.Where("category_id = ?", categoryID)
 	}
 
+	if result != nil {
+		query = result.Order(query)
+	}
 	query.Find(&links)
+	search.LogQuery(h.db, keyword, userID, c.ClientIP(), search.SourceWeb, int64(len(links)))
 
 	// 按分类组织链接
 	categoryLinksMap := make(map[uint][]models.Link)
@@ -72,7 +84,7 @@ func (h *WebHandler) Home(c *gin.Context) {
 	var tags []models.Tag
 	h.db.Joins("JOIN link_tags ON link_tags.tag_id = tags.id").
 		Joins("JOIN links ON links.id = link_tags.link_id").
-		Where("links.status = ?", "active").
+		Where("links.status IN ?", models.VisibleLinkStatuses).
 		Group("tags.id").
 		Order("COUNT(links.id) DESC").
 		Limit(20).
@@ -85,7 +97,7 @@ func (h *WebHandler) Home(c *gin.Context) {
 		TotalClicks     int64
 		TodayClicks     int64
 	}
-	h.db.Model(&models.Link{}).Where("status = ?", "active").Count(&stats.TotalLinks)
+	h.db.Model(&models.Link{}).Where("status IN ?", models.VisibleLinkStatuses).Count(&stats.TotalLinks)
 	h.db.Model(&models.Category{}).Where("active = ?", true).Count(&stats.TotalCategories)
 	h.db.Model(&models.ClickLog{}).Count(&stats.TotalClicks)
 
@@ -96,14 +108,14 @@ func (h *WebHandler) Home(c *gin.Context) {
 	// 渲染模板
 	// 渲染布局模板，它会查找 "home-content" block
 	c.HTML(http.StatusOK, "layouts/base.html", gin.H{
-		"Title":           "首页",
-		"User":            user,
-		"Categories":      categories,
+		"Title":            "首页",
+		"User":             user,
+		"Categories":       categories,
 		"CategoryLinksMap": categoryLinksMap,
-		"Tags":            tags,
-		"Search":          search,
-		"Tag":             tag,
-		"Stats":           stats,
+		"Tags":             tags,
+		"Search":           keyword,
+		"Tag":              tag,
+		"Stats":            stats,
 	})
 }
 
@@ -148,7 +160,7 @@ func (h *WebHandler) AdminDashboard(c *gin.Context) {
 		TotalTags       int64
 		TotalUsers      int64
 		TotalClicks     int64
-		TodayClicks      int64
+		TodayClicks     int64
 		ThisWeekClicks  int64
 		ThisMonthClicks int64
 	}
@@ -192,11 +204,11 @@ func (h *WebHandler) AdminDashboard(c *gin.Context) {
 		Find(&recentClicks)
 
 	c.HTML(http.StatusOK, "layouts/admin.html", gin.H{
-		"Title":         "仪表盘",
-		"User":          user,
-		"Stats":         stats,
-		"PopularLinks":  popularLinks,
-		"RecentClicks":  recentClicks,
+		"Title":        "仪表盘",
+		"User":         user,
+		"Stats":        stats,
+		"PopularLinks": popularLinks,
+		"RecentClicks": recentClicks,
 	})
 }
 
@@ -0,0 +1,166 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package httpclient 创建后端所有对外请求使用的 HTTP 客户端，
+// 统一应用代理、自定义 CA 和 User-Agent 配置。
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"kk-nav/internal/config"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultUserAgent 未配置时使用的 User-Agent
+const DefaultUserAgent = "kk-nav/1.0 (+https://github.com/kevin197011/kk-nav)"
+
+var (
+	mu        sync.RWMutex
+	proxyFunc = http.ProxyFromEnvironment
+	rootCAs   *x509.CertPool
+	userAgent = DefaultUserAgent
+)
+
+// Options 客户端参数
+type Options struct {
+	Timeout             time.Duration
+	MaxIdleConnsPerHost int
+	InsecureSkipVerify  bool // 跳过证书校验（仅用于链接级别的显式配置）
+	NoRedirect          bool // 不跟随重定向，直接返回 3xx 响应
+	CheckRedirect       func(req *http.Request, via []*http.Request) error
+}
+
+// Init 加载出站请求配置，启动时调用一次。
+// 没有配置代理时使用标准环境变量 HTTP_PROXY / HTTPS_PROXY / NO_PROXY。
+func Init(cfg config.OutboundConfig) error {
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return fmt.Errorf("invalid outbound proxy: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported outbound proxy scheme: %s", u.Scheme)
+		}
+
+		fn := (&httpproxy.Config{
+			HTTPProxy:  cfg.Proxy,
+			HTTPSProxy: cfg.Proxy,
+			NoProxy:    cfg.NoProxy,
+		}).ProxyFunc()
+		proxy = func(req *http.Request) (*url.URL, error) {
+			return fn(req.URL)
+		}
+	}
+
+	var pool *x509.CertPool
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("read outbound CA file: %w", err)
+		}
+		if pool, err = x509.SystemCertPool(); err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+	}
+
+	ua := cfg.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+
+	mu.Lock()
+	proxyFunc = proxy
+	rootCAs = pool
+	userAgent = ua
+	mu.Unlock()
+	return nil
+}
+
+// UserAgent 返回出站请求的 User-Agent
+func UserAgent() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return userAgent
+}
+
+// NewTransport 创建应用了代理和 CA 配置的 Transport
+func NewTransport(opts Options) *http.Transport {
+	mu.RLock()
+	proxy, pool := proxyFunc, rootCAs
+	mu.RUnlock()
+
+	perHost := opts.MaxIdleConnsPerHost
+	if perHost <= 0 {
+		perHost = http.DefaultMaxIdleConnsPerHost
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig: &tls.Config{
+			RootCAs:            pool,
+			InsecureSkipVerify: opts.InsecureSkipVerify,
+		},
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   perHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// New 创建 HTTP 客户端
+func New(opts Options) *http.Client {
+	return NewWithTransport(NewTransport(opts), opts)
+}
+
+// NewWithTransport 基于已有 Transport 创建客户端（多个客户端共享连接池时使用）
+func NewWithTransport(transport http.RoundTripper, opts Options) *http.Client {
+	client := &http.Client{
+		Transport:     &userAgentTransport{base: transport},
+		Timeout:       opts.Timeout,
+		CheckRedirect: opts.CheckRedirect,
+	}
+	if opts.NoRedirect {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// userAgentTransport 请求没有设置 User-Agent 时使用配置的值
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent())
+	}
+	return t.base.RoundTrip(req)
+}
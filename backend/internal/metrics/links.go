@@ -0,0 +1,109 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"kk-nav/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// collectTimeout 单次抓取查询数据库的超时时间
+const collectTimeout = 10 * time.Second
+
+var linkLabels = []string{"link_id", "link", "category", "url"}
+
+var (
+	linkUpDesc = prometheus.NewDesc(namespace+"_link_up",
+		"Whether the last probe of the link succeeded (1) or failed (0).", linkLabels, nil)
+	linkLatencyDesc = prometheus.NewDesc(namespace+"_link_latency_seconds",
+		"Latency of the last probe of the link.", linkLabels, nil)
+	linkStatusCodeDesc = prometheus.NewDesc(namespace+"_link_status_code",
+		"HTTP status code of the last probe of the link, 0 when no response was received.", linkLabels, nil)
+	linkLastCheckDesc = prometheus.NewDesc(namespace+"_link_last_check_timestamp_seconds",
+		"Unix time of the last probe of the link.", linkLabels, nil)
+	linkStatusDesc = prometheus.NewDesc(namespace+"_link_status",
+		"Stored status of the link after failure thresholds (always 1, see the status label).",
+		append(append([]string{}, linkLabels...), "status"), nil)
+	linkCertExpiryDesc = prometheus.NewDesc(namespace+"_link_cert_expiry_timestamp_seconds",
+		"Unix time the TLS certificate of the link expires.", linkLabels, nil)
+	linkScrapeErrorDesc = prometheus.NewDesc(namespace+"_link_scrape_error",
+		"Whether reading link health from the database failed (1) during this scrape.", nil, nil)
+)
+
+// linkCollector 抓取时从数据库读取每个链接最近一次的检测结果
+type linkCollector struct {
+	db *gorm.DB
+}
+
+func newLinkCollector(db *gorm.DB) *linkCollector {
+	return &linkCollector{db: db}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *linkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- linkUpDesc
+	ch <- linkLatencyDesc
+	ch <- linkStatusCodeDesc
+	ch <- linkLastCheckDesc
+	ch <- linkStatusDesc
+	ch <- linkCertExpiryDesc
+	ch <- linkScrapeErrorDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *linkCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	db := c.db.WithContext(ctx)
+
+	var links []models.Link
+	if err := db.Preload("Category").Find(&links).Error; err != nil {
+		ch <- prometheus.MustNewConstMetric(linkScrapeErrorDesc, prometheus.GaugeValue, 1)
+		return
+	}
+
+	// 每个链接最新的一条检测结果
+	var results []models.LinkCheckResult
+	latest := db.Model(&models.LinkCheckResult{}).Select("MAX(id)").Group("link_id")
+	if err := db.Where("id IN (?)", latest).Find(&results).Error; err != nil {
+		ch <- prometheus.MustNewConstMetric(linkScrapeErrorDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(linkScrapeErrorDesc, prometheus.GaugeValue, 0)
+
+	byLink := make(map[uint]*models.LinkCheckResult, len(results))
+	for i := range results {
+		byLink[results[i].LinkID] = &results[i]
+	}
+
+	for _, link := range links {
+		labels := []string{strconv.FormatUint(uint64(link.ID), 10), link.Title, link.Category.Name, link.URL}
+
+		ch <- prometheus.MustNewConstMetric(linkStatusDesc, prometheus.GaugeValue, 1, append(labels, link.Status)...)
+		if expires := link.Certificate.ExpiresAt; expires != nil {
+			ch <- prometheus.MustNewConstMetric(linkCertExpiryDesc, prometheus.GaugeValue, float64(expires.Unix()), labels...)
+		}
+
+		result, ok := byLink[link.ID]
+		if !ok {
+			continue
+		}
+		up := 0.0
+		if result.IsUp() {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(linkUpDesc, prometheus.GaugeValue, up, labels...)
+		ch <- prometheus.MustNewConstMetric(linkLatencyDesc, prometheus.GaugeValue, float64(result.LatencyMs)/1000, labels...)
+		ch <- prometheus.MustNewConstMetric(linkStatusCodeDesc, prometheus.GaugeValue, float64(result.StatusCode), labels...)
+		ch <- prometheus.MustNewConstMetric(linkLastCheckDesc, prometheus.GaugeValue, float64(result.CheckedAt.Unix()), labels...)
+	}
+}
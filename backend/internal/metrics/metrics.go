@@ -0,0 +1,121 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// namespace 指标名前缀
+const namespace = "kknav"
+
+// registry 独立的指标注册表（不使用全局默认注册表，避免第三方库注册的指标混入）
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	checkerRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "checker_run_duration_seconds",
+		Help:      "Duration of link checker runs.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200},
+	}, []string{"trigger"})
+
+	checkerRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checker_runs_total",
+		Help:      "Link checker runs by trigger and outcome.",
+	}, []string{"trigger", "outcome"})
+
+	checkerLinksChecked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checker_links_checked_total",
+		Help:      "Links checked by scheduled and manual runs, by probe result.",
+	}, []string{"result"})
+
+	checkerLastRun = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "checker_last_run_timestamp_seconds",
+		Help:      "Unix time the last link checker run finished.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		checkerRunDuration,
+		checkerRuns,
+		checkerLinksChecked,
+		checkerLastRun,
+	)
+}
+
+// RegisterDB 注册数据库连接池和链接健康指标（在数据库连接后调用一次）
+func RegisterDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return registerAll(
+		collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()),
+		newLinkCollector(db),
+	)
+}
+
+func registerAll(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 指标导出 Handler
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest 记录一次 HTTP 请求，route 为路由模板（如 /api/v1/links/:id）
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveCheckerRun 记录一次链接检测
+func ObserveCheckerRun(trigger string, duration time.Duration, up, down int64, cancelled bool) {
+	outcome := "completed"
+	if cancelled {
+		outcome = "cancelled"
+	}
+	checkerRuns.WithLabelValues(trigger, outcome).Inc()
+	checkerRunDuration.WithLabelValues(trigger).Observe(duration.Seconds())
+	checkerLinksChecked.WithLabelValues("up").Add(float64(up))
+	checkerLinksChecked.WithLabelValues("down").Add(float64(down))
+	checkerLastRun.SetToCurrentTime()
+}
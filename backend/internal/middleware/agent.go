@@ -0,0 +1,86 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"kk-nav/internal/database"
+	"kk-nav/internal/models"
+	"kk-nav/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AgentMiddleware 检测代理认证中间件：只接受 scope 为 agent 且已绑定检测代理的 API Token，并记录代理最近活动时间
+func AgentMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !strings.HasPrefix(token, "kk_") {
+			utils.Unauthorized(c, "Agent token required")
+			c.Abort()
+			return
+		}
+
+		var apiToken models.APIToken
+		if err := database.DB.Where("token = ?", token).First(&apiToken).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.Unauthorized(c, "Invalid API token")
+				c.Abort()
+				return
+			}
+			utils.InternalServerError(c, "Database error")
+			c.Abort()
+			return
+		}
+		if !apiToken.IsValid() {
+			utils.Unauthorized(c, "Token is inactive or expired")
+			c.Abort()
+			return
+		}
+		if apiToken.Scope != models.TokenScopeAgent {
+			utils.Forbidden(c, "Not an agent token")
+			c.Abort()
+			return
+		}
+
+		var agent models.Agent
+		if err := database.DB.Where("api_token_id = ?", apiToken.ID).First(&agent).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.Forbidden(c, "Token is not bound to an agent")
+				c.Abort()
+				return
+			}
+			utils.InternalServerError(c, "Database error")
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		database.DB.Model(&apiToken).UpdateColumn("last_used_at", now)
+		agent.LastSeenAt = &now
+		agent.RemoteIP = c.ClientIP()
+		database.DB.Model(&agent).UpdateColumns(map[string]interface{}{
+			"last_seen_at": now,
+			"remote_ip":    agent.RemoteIP,
+		})
+
+		c.Set("agent", &agent)
+		c.Next()
+	}
+}
+
+// GetAgent 从上下文获取当前检测代理
+func GetAgent(c *gin.Context) (*models.Agent, bool) {
+	value, exists := c.Get("agent")
+	if !exists {
+		return nil, false
+	}
+	agent, ok := value.(*models.Agent)
+	return agent, ok
+}
@@ -6,6 +6,7 @@
 package middleware
 
 import (
+	"net/http"
 	"strings"
 	"time"
 
@@ -19,73 +20,94 @@ import (
 // AuthMiddleware JWT认证中间件（支持 JWT Token 和 API Token）
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.Unauthorized(c, "Authorization header required")
+		if status, message := authenticate(c); status != 0 {
+			switch status {
+			case http.StatusForbidden:
+				utils.Forbidden(c, message)
+			case http.StatusInternalServerError:
+				utils.InternalServerError(c, message)
+			default:
+				utils.Unauthorized(c, message)
+			}
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// 提取Token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.Unauthorized(c, "Invalid authorization header format")
-			c.Abort()
-			return
+// OptionalAuthMiddleware 可选认证：Token 有效时把用户信息存入上下文，没有或无效（如已过期、已吊销）时按匿名访问放行
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c)
 		}
+		c.Next()
+	}
+}
 
-		token := parts[1]
-
-		// 判断是 API Token 还是 JWT Token
-		if strings.HasPrefix(token, "kk_") {
-			// API Token 认证
-			var apiToken models.APIToken
-			if err := database.DB.Preload("User").Where("token = ?", token).First(&apiToken).Error; err != nil {
-				if err == gorm.ErrRecordNotFound {
-					utils.Unauthorized(c, "Invalid API token")
-					c.Abort()
-					return
-				}
-				utils.InternalServerError(c, "Database error")
-				c.Abort()
-				return
-			}
+// authenticate 校验 Authorization 头（JWT Token 或 API Token），成功时把用户信息存入上下文并返回 0，
+// 失败时返回 HTTP 状态码和错误信息，不写入响应
+func authenticate(c *gin.Context) (int, string) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return http.StatusUnauthorized, "Authorization header required"
+	}
 
-			// 检查 Token 是否有效
-			if !apiToken.IsValid() {
-				utils.Unauthorized(c, "Token is inactive or expired")
-				c.Abort()
-				return
+	// 提取Token
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return http.StatusUnauthorized, "Invalid authorization header format"
+	}
+
+	token := parts[1]
+
+	// 判断是 API Token 还是 JWT Token
+	if strings.HasPrefix(token, "kk_") {
+		// API Token 认证
+		var apiToken models.APIToken
+		if err := database.DB.Preload("User").Where("token = ?", token).First(&apiToken).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return http.StatusUnauthorized, "Invalid API token"
 			}
+			return http.StatusInternalServerError, "Database error"
+		}
 
-			// 更新最后使用时间
-			now := time.Now()
-			apiToken.LastUsedAt = &now
-			database.DB.Save(&apiToken)
-
-			// 将用户信息存储到上下文
-			c.Set("user_id", apiToken.UserID)
-			c.Set("username", apiToken.User.Username)
-			c.Set("email", apiToken.User.Email)
-			c.Set("role", apiToken.User.Role)
-		} else {
-			// JWT Token 认证
-		claims, err := utils.ParseToken(token)
-		if err != nil {
-			utils.Unauthorized(c, "Invalid or expired token")
-			c.Abort()
-			return
+		// 检查 Token 是否有效
+		if !apiToken.IsValid() {
+			return http.StatusUnauthorized, "Token is inactive or expired"
 		}
 
-		// 将用户信息存储到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("email", claims.Email)
-		c.Set("role", claims.Role)
+		// 检测代理的 Token 不能代表用户访问其他接口
+		if apiToken.Scope == models.TokenScopeAgent {
+			return http.StatusForbidden, "Agent tokens can only access the agent API"
 		}
 
-		c.Next()
+		// 更新最后使用时间
+		now := time.Now()
+		apiToken.LastUsedAt = &now
+		database.DB.Save(&apiToken)
+
+		// 将用户信息存储到上下文
+		c.Set("user_id", apiToken.UserID)
+		c.Set("username", apiToken.User.Username)
+		c.Set("email", apiToken.User.Email)
+		c.Set("role", apiToken.User.Role)
+		return 0, ""
 	}
+
+	// JWT Token 认证
+	claims, err := utils.ParseToken(token)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	// 将用户信息存储到上下文
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	return 0, ""
 }
 
 // AdminMiddleware 管理员权限中间件
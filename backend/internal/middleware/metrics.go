@@ -0,0 +1,80 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"kk-nav/internal/config"
+	"kk-nav/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware 按路由模板记录请求数和耗时（未匹配的路由统一记为 unmatched，避免标签基数失控）
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsAuthMiddleware 指标访问控制：配置了 Token 时校验 Bearer Token，配置了 IP 白名单时校验来源 IP（两者都配置时都需满足）
+func MetricsAuthMiddleware(cfg config.MetricsConfig) gin.HandlerFunc {
+	var networks []*net.IPNet
+	for _, item := range cfg.AllowedIPs {
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(item); err == nil {
+			networks = append(networks, network)
+		}
+	}
+
+	return func(c *gin.Context) {
+		// 配置了白名单但没有可用的条目时拒绝所有请求，而不是放行
+		if len(cfg.AllowedIPs) > 0 {
+			// 使用连接的来源地址，不信任 X-Forwarded-For
+			ip := net.ParseIP(c.RemoteIP())
+			allowed := false
+			for _, network := range networks {
+				if ip != nil && network.Contains(ip) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+
+		if cfg.Token != "" {
+			token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// zonePattern 检测区域名称
+var zonePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// Agent 远程检测代理，负责检测所在区域（隔离网络）内的链接
+type Agent struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Name         string     `gorm:"not null;size:255;unique" json:"name"`
+	Zone         string     `gorm:"not null;size:100;index" json:"zone"`
+	APITokenID   uint       `gorm:"not null;uniqueIndex" json:"api_token_id"` // 代理认证使用的 API Token（scope 为 agent）
+	Version      string     `gorm:"size:50" json:"version"`
+	Hostname     string     `gorm:"size:255" json:"hostname"`
+	RemoteIP     string     `gorm:"size:64" json:"remote_ip"`
+	LastSeenAt   *time.Time `json:"last_seen_at"`   // 最近一次请求（心跳、拉取链接、上报结果）
+	LastReportAt *time.Time `json:"last_report_at"` // 最近一次上报检测结果
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Agent) TableName() string {
+	return "agents"
+}
+
+// IsOnline 最近 timeout 内是否有请求
+func (a *Agent) IsOnline(now time.Time, timeout time.Duration) bool {
+	return a.LastSeenAt != nil && now.Sub(*a.LastSeenAt) <= timeout
+}
+
+// ValidateZone 校验检测区域名称（字母、数字、点、下划线和短横线）
+func ValidateZone(zone string) error {
+	if !zonePattern.MatchString(zone) {
+		return fmt.Errorf("invalid zone %q: use letters, digits, '.', '_' or '-'", zone)
+	}
+	return nil
+}
@@ -23,10 +23,14 @@ type APIToken struct {
 	LastUsedAt  *time.Time `json:"last_used_at"`
 	ExpiresAt   *time.Time `json:"expires_at"`
 	Active      bool      `gorm:"not null;default:true" json:"active"`
+	Scope       string    `gorm:"not null;default:'';size:20" json:"scope"` // 为空时代表所属用户，agent 只能访问检测代理接口
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// TokenScopeAgent 检测代理使用的 Token
+const TokenScopeAgent = "agent"
+
 // TableName 指定表名
 func (APIToken) TableName() string {
 	return "api_tokens"
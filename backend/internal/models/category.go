@@ -13,15 +13,17 @@ import (
 
 // Category 分类模型
 type Category struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `gorm:"uniqueIndex;not null;size:100" json:"name" binding:"required,min=1,max=100"`
-	Icon        string    `gorm:"not null;default:'📁';size:50" json:"icon" binding:"required"`
-	Description string    `gorm:"type:text" json:"description"`
-	Color       string    `gorm:"not null;default:'#007bff';size:7" json:"color" binding:"required"`
-	SortOrder   int       `gorm:"uniqueIndex;not null" json:"sort_order"`
-	Active      bool      `gorm:"not null;default:true" json:"active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	Name               string    `gorm:"uniqueIndex;not null;size:100" json:"name" binding:"required,min=1,max=100"`
+	NamePinyin         string    `gorm:"type:text;not null;default:''" json:"-"` // 名称的拼音检索词，保存时计算
+	Icon               string    `gorm:"not null;default:'📁';size:50" json:"icon" binding:"required"`
+	Description        string    `gorm:"type:text" json:"description"`
+	Color              string    `gorm:"not null;default:'#007bff';size:7" json:"color" binding:"required"`
+	SortOrder          int       `gorm:"uniqueIndex;not null" json:"sort_order"`
+	Active             bool      `gorm:"not null;default:true" json:"active"`
+	HiddenOnStatusPage bool      `gorm:"not null;default:false" json:"hidden_on_status_page"` // 不在公开状态页展示（敏感分类）
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 
 	// 关联
 	Links []Link `gorm:"foreignKey:CategoryID" json:"links,omitempty"`
@@ -37,7 +39,7 @@ func (c *Category) LinksCount() int64 {
 	var count int64
 	db := GetDB()
 	if db != nil {
-		db.Model(&Link{}).Where("category_id = ? AND status = ?", c.ID, "active").Count(&count)
+		db.Model(&Link{}).Where("category_id = ? AND status IN ?", c.ID, VisibleLinkStatuses).Count(&count)
 	}
 	return count
 }
@@ -52,6 +54,14 @@ func (c *Category) TotalClicks() int64 {
 	return total
 }
 
+// BeforeSave 保存前钩子
+func (c *Category) BeforeSave(tx *gorm.DB) error {
+	if c.Name != "" {
+		c.NamePinyin = Pinyin(c.Name)
+	}
+	return nil
+}
+
 // BeforeCreate 创建前钩子
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
 	if c.SortOrder == 0 {
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+// 链接检测失败原因分类（值会持久化并用于筛选，只能新增不能修改）
+const (
+	ErrorClassInvalidConfig    = "invalid_config"     // 探测配置无效（如关键字正则错误）
+	ErrorClassInvalidURL       = "invalid_url"        // 链接地址无法解析
+	ErrorClassDNS              = "dns"                // 域名解析失败
+	ErrorClassConnRefused      = "connection_refused" // 连接被拒绝
+	ErrorClassConnReset        = "connection_reset"   // 连接被重置
+	ErrorClassTimeout          = "timeout"            // 连接或响应超时
+	ErrorClassTLS              = "tls"                // TLS 握手失败
+	ErrorClassTLSExpired       = "tls_expired"        // 证书过期或尚未生效
+	ErrorClassTLSHostname      = "tls_hostname"       // 证书与主机名不匹配
+	ErrorClassTLSUntrusted     = "tls_untrusted"      // 证书不受信任（自签名、缺少中间证书）
+	ErrorClassTooManyRedirects = "too_many_redirects" // 重定向次数过多或循环重定向
+	ErrorClassHTTP4xx          = "http_4xx"           // 非预期的 4xx 状态码
+	ErrorClassHTTP5xx          = "http_5xx"           // 非预期的 5xx 状态码
+	ErrorClassHTTPStatus       = "http_status"        // 其他非预期状态码
+	ErrorClassKeywordMismatch  = "keyword_mismatch"   // 响应体关键字不匹配
+	ErrorClassNetwork          = "network"            // 其他网络错误
+)
+
+// ErrorClasses 所有失败原因分类
+var ErrorClasses = []string{
+	ErrorClassInvalidConfig,
+	ErrorClassInvalidURL,
+	ErrorClassDNS,
+	ErrorClassConnRefused,
+	ErrorClassConnReset,
+	ErrorClassTimeout,
+	ErrorClassTLS,
+	ErrorClassTLSExpired,
+	ErrorClassTLSHostname,
+	ErrorClassTLSUntrusted,
+	ErrorClassTooManyRedirects,
+	ErrorClassHTTP4xx,
+	ErrorClassHTTP5xx,
+	ErrorClassHTTPStatus,
+	ErrorClassKeywordMismatch,
+	ErrorClassNetwork,
+}
+
+// IsErrorClass 判断是否为已知的失败原因分类
+func IsErrorClass(class string) bool {
+	for _, c := range ErrorClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -11,13 +11,14 @@ import (
 
 // ClickLog 点击日志模型
 type ClickLog struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	LinkID    uint      `gorm:"not null;index" json:"link_id" binding:"required"`
-	UserID    *uint     `gorm:"index" json:"user_id,omitempty"`
-	IPAddress string    `gorm:"not null;size:45" json:"ip_address" binding:"required"`
-	UserAgent string    `gorm:"not null;type:text" json:"user_agent" binding:"required"`
-	Referer   string    `gorm:"type:text" json:"referer"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	LinkID      uint      `gorm:"not null;index" json:"link_id" binding:"required"`
+	UserID      *uint     `gorm:"index" json:"user_id,omitempty"`
+	IPAddress   string    `gorm:"not null;size:45" json:"ip_address" binding:"required"`
+	UserAgent   string    `gorm:"not null;type:text" json:"user_agent" binding:"required"`
+	Referer     string    `gorm:"type:text" json:"referer"`
+	Environment string    `gorm:"not null;default:'';size:50" json:"environment"` // 跳转的环境，使用链接 URL 时为空
+	CreatedAt   time.Time `json:"created_at"`
 
 	// 关联
 	Link Link  `gorm:"foreignKey:LinkID" json:"link,omitempty"`
@@ -28,4 +29,3 @@ type ClickLog struct {
 func (ClickLog) TableName() string {
 	return "click_logs"
 }
-
@@ -13,25 +13,62 @@ import (
 	"gorm.io/gorm"
 )
 
+// 链接状态
+const (
+	LinkStatusActive   = "active"
+	LinkStatusDegraded = "degraded" // 连续失败达到 degraded 阈值但尚未达到失败阈值
+	LinkStatusError    = "error"
+	LinkStatusInactive = "inactive" // 手动禁用，不参与检测
+)
+
+// VisibleLinkStatuses 前台可见的链接状态
+var VisibleLinkStatuses = []string{LinkStatusActive, LinkStatusDegraded}
+
+// CheckedLinkStatuses 参与定时检测的链接状态
+var CheckedLinkStatuses = []string{LinkStatusActive, LinkStatusDegraded, LinkStatusError}
+
 // Link 链接模型
 type Link struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	Title         string    `gorm:"not null;size:255;unique" json:"title" binding:"required,min=1,max=255"`
-	URL           string    `gorm:"not null;type:text" json:"url" binding:"required,url"`
-	Description   string    `gorm:"type:text" json:"description"`
-	CategoryID    uint      `gorm:"not null;index" json:"category_id" binding:"required"`
-	SortOrder     int       `gorm:"not null" json:"sort_order"`
-	Status        string    `gorm:"not null;default:'active';size:20;index" json:"status"` // active | inactive | error
-	ClickCount    int       `gorm:"not null;default:0" json:"click_count"`
-	LastCheckedAt *time.Time `gorm:"type:timestamp" json:"last_checked_at"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	// 检测诊断信息（错误、证书、重定向等）和探测配置不在公开接口返回，管理后台使用 AdminLink
+	ID                   uint            `gorm:"primaryKey" json:"id"`
+	Title                string          `gorm:"not null;size:255;unique" json:"title" binding:"required,min=1,max=255"`
+	TitlePinyin          string          `gorm:"type:text;not null;default:''" json:"-"` // 标题的拼音检索词，保存时计算
+	Slug                 *string         `gorm:"size:100;uniqueIndex" json:"slug"`       // 短链接名，/go/<slug> 跳转到链接，为空表示不设置
+	URL                  string          `gorm:"not null;type:text" json:"url" binding:"required,url"`
+	URLVariables         []URLVariable   `gorm:"serializer:json;type:text" json:"url_variables,omitempty"` // URL 模板变量，为空时 URL 不是模板
+	IconHash             string          `gorm:"size:64;not null;default:''" json:"icon_hash"`             // 图标内容的哈希，用于 /links/:id/icon?v= 缓存；为空时使用字母图标
+	Description          string          `gorm:"type:text" json:"description"`
+	CategoryID           uint            `gorm:"not null;index" json:"category_id" binding:"required"`
+	SortOrder            int             `gorm:"not null" json:"sort_order"`
+	Status               string          `gorm:"not null;default:'active';size:20;index" json:"status"` // active | degraded | inactive | error
+	ClickCount           int             `gorm:"not null;default:0" json:"click_count"`
+	LastCheckedAt        *time.Time      `gorm:"type:timestamp" json:"last_checked_at"`
+	LastStatusCode       int             `gorm:"not null;default:0" json:"-"` // 最近一次检测的 HTTP 状态码，未收到响应时为 0
+	LastErrorClass       string          `gorm:"size:50;index" json:"-"`      // 最近一次检测的失败原因分类，成功时为空
+	LastError            string          `gorm:"type:text" json:"-"`          // 最近一次检测的错误信息（可能包含内部主机名和 IP）
+	ConsecutiveFailures  int             `gorm:"not null;default:0" json:"-"`
+	ConsecutiveSuccesses int             `gorm:"not null;default:0" json:"-"`
+	Zone                 string          `gorm:"not null;default:'';size:100;index" json:"zone"` // 检测区域，为空时由中心检测服务检测，否则由该区域的检测代理检测
+	Probe                LinkProbe       `gorm:"embedded;embeddedPrefix:probe_" json:"-"`        // 可能包含认证请求头，只在管理后台（AdminLink）返回
+	Certificate          LinkCertificate `gorm:"embedded;embeddedPrefix:cert_" json:"-"`
+	Redirect             LinkRedirect    `gorm:"embedded;embeddedPrefix:redirect_" json:"-"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+
+	// 维护状态（不入库，由当前生效的维护窗口计算）
+	Maintenance        bool   `gorm:"-" json:"maintenance"`
+	MaintenanceMessage string `gorm:"-" json:"maintenance_message,omitempty"`
+
+	// 搜索命中片段（不入库，字段名 -> 用 <mark> 标记命中的 HTML 片段）
+	Highlight map[string]string `gorm:"-" json:"highlight,omitempty"`
 
 	// 关联
-	Category   Category    `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Tags       []Tag       `gorm:"many2many:link_tags;" json:"tags,omitempty"`
-	Favorites  []Favorite  `gorm:"foreignKey:LinkID" json:"favorites,omitempty"`
-	ClickLogs  []ClickLog  `gorm:"foreignKey:LinkID" json:"click_logs,omitempty"`
+	Category  Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Tags      []Tag          `gorm:"many2many:link_tags;" json:"tags,omitempty"`
+	Endpoints []LinkEndpoint `gorm:"foreignKey:LinkID" json:"endpoints,omitempty"` // 各环境的访问地址
+	Aliases   []LinkAlias    `gorm:"foreignKey:LinkID" json:"aliases,omitempty"`   // 短链接别名
+	Favorites []Favorite     `gorm:"foreignKey:LinkID" json:"favorites,omitempty"`
+	ClickLogs []ClickLog     `gorm:"foreignKey:LinkID" json:"click_logs,omitempty"`
 }
 
 // TableName 指定表名
@@ -44,6 +81,11 @@ func (l *Link) IsActive() bool {
 	return l.Status == "active"
 }
 
+// IsVisible 判断前台是否可见
+func (l *Link) IsVisible() bool {
+	return l.Status == LinkStatusActive || l.Status == LinkStatusDegraded
+}
+
 // IncrementClickCount 增加点击数
 func (l *Link) IncrementClickCount() error {
 	db := GetDB()
@@ -67,11 +109,16 @@ func (l *Link) BeforeCreate(tx *gorm.DB) error {
 		return err
 	}
 
+	l.TitlePinyin = Pinyin(l.Title)
 	return nil
 }
 
 // BeforeUpdate 更新前钩子
 func (l *Link) BeforeUpdate(tx *gorm.DB) error {
+	if l.Title != "" {
+		l.TitlePinyin = Pinyin(l.Title)
+	}
+
 	// 规范化URL
 	return l.normalizeURL()
 }
@@ -88,13 +135,11 @@ func (l *Link) normalizeURL() error {
 		urlStr = "https://" + urlStr
 	}
 
-	// 验证URL格式
-	_, err := url.Parse(urlStr)
-	if err != nil {
-		return err
-	}
-
+	// 验证URL格式，模板按默认值展开后验证
 	l.URL = urlStr
-	return nil
+	if l.IsTemplate() {
+		return l.ValidateURLTemplate()
+	}
+	_, err := url.Parse(urlStr)
+	return err
 }
-
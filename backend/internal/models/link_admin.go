@@ -0,0 +1,64 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+// AdminLink 管理后台的链接视图：在公开字段之外返回探测配置和检测诊断信息（错误、证书、重定向等），
+// 这些信息可能包含认证请求头、内部主机名和 IP，不能出现在公开接口中
+type AdminLink struct {
+	Link
+	LastStatusCode       int             `json:"last_status_code"`
+	LastErrorClass       string          `json:"last_error_class"`
+	LastError            string          `json:"last_error"`
+	ConsecutiveFailures  int             `json:"consecutive_failures"`
+	ConsecutiveSuccesses int             `json:"consecutive_successes"`
+	Probe                LinkProbe       `json:"probe"`
+	Certificate          LinkCertificate `json:"certificate"`
+	Redirect             LinkRedirect    `json:"redirect"`
+	Endpoints            []AdminEndpoint `json:"endpoints,omitempty"`
+}
+
+// AdminEndpoint 管理后台的环境地址视图，包含检测诊断信息
+type AdminEndpoint struct {
+	LinkEndpoint
+	LastStatusCode int    `json:"last_status_code"`
+	LastLatencyMs  int64  `json:"last_latency_ms"`
+	LastErrorClass string `json:"last_error_class"`
+	LastError      string `json:"last_error"`
+}
+
+// NewAdminLink 创建管理后台的链接视图
+func NewAdminLink(l Link) AdminLink {
+	a := AdminLink{
+		Link:                 l,
+		LastStatusCode:       l.LastStatusCode,
+		LastErrorClass:       l.LastErrorClass,
+		LastError:            l.LastError,
+		ConsecutiveFailures:  l.ConsecutiveFailures,
+		ConsecutiveSuccesses: l.ConsecutiveSuccesses,
+		Probe:                l.Probe,
+		Certificate:          l.Certificate,
+		Redirect:             l.Redirect,
+	}
+	for _, e := range l.Endpoints {
+		a.Endpoints = append(a.Endpoints, AdminEndpoint{
+			LinkEndpoint:   e,
+			LastStatusCode: e.LastStatusCode,
+			LastLatencyMs:  e.LastLatencyMs,
+			LastErrorClass: e.LastErrorClass,
+			LastError:      e.LastError,
+		})
+	}
+	return a
+}
+
+// NewAdminLinks 批量创建管理后台的链接视图
+func NewAdminLinks(links []Link) []AdminLink {
+	items := make([]AdminLink, len(links))
+	for i := range links {
+		items[i] = NewAdminLink(links[i])
+	}
+	return items
+}
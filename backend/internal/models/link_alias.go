@@ -0,0 +1,151 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxLinkAliases 每个链接最多的别名数
+const MaxLinkAliases = 20
+
+// slugPattern 短链接名：小写字母、数字、点、下划线和短横线，不含 /（/ 之后的部分原样追加到目标地址）
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+
+// LinkAlias 链接的别名，与 Link.Slug 共用同一个命名空间，/go/<别名> 跳转到链接
+type LinkAlias struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	LinkID    uint      `gorm:"not null;index" json:"link_id"`
+	Slug      string    `gorm:"not null;size:100;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (LinkAlias) TableName() string {
+	return "link_aliases"
+}
+
+// SlugConflict 已被其他链接使用的短链接名
+type SlugConflict struct {
+	Slug      string `json:"slug"`
+	Kind      string `json:"kind"` // slug | alias
+	LinkID    uint   `json:"link_id"`
+	LinkTitle string `json:"link_title"`
+}
+
+// NormalizeSlug 短链接名去掉首尾空白和 /，并转小写
+func NormalizeSlug(slug string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(slug), "/"))
+}
+
+// ValidateSlug 校验短链接名
+func ValidateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q: use lowercase letters, digits, '.', '_' or '-' (max 100)", slug)
+	}
+	return nil
+}
+
+// SetSlugs 规范化并校验短链接名和别名，slug 为空表示不设置
+func (l *Link) SetSlugs(slug string, aliases []string) error {
+	if len(aliases) > MaxLinkAliases {
+		return fmt.Errorf("a link can have at most %d aliases", MaxLinkAliases)
+	}
+
+	seen := make(map[string]bool, len(aliases)+1)
+	slug = NormalizeSlug(slug)
+	if slug != "" {
+		if err := ValidateSlug(slug); err != nil {
+			return err
+		}
+		seen[slug] = true
+	}
+
+	items := make([]LinkAlias, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = NormalizeSlug(alias)
+		if err := ValidateSlug(alias); err != nil {
+			return err
+		}
+		if seen[alias] {
+			return fmt.Errorf("duplicate slug %q", alias)
+		}
+		seen[alias] = true
+		items = append(items, LinkAlias{Slug: alias})
+	}
+
+	l.Slug = nil
+	if slug != "" {
+		l.Slug = &slug
+	}
+	l.Aliases = items
+	return nil
+}
+
+// Slugs 链接的短链接名和所有别名
+func (l *Link) Slugs() []string {
+	var slugs []string
+	if l.Slug != nil {
+		slugs = append(slugs, *l.Slug)
+	}
+	for _, a := range l.Aliases {
+		slugs = append(slugs, a.Slug)
+	}
+	return slugs
+}
+
+// FindSlugConflicts 查找已被其他链接（ID 不为 linkID）用作短链接名或别名的 slugs
+func FindSlugConflicts(db *gorm.DB, linkID uint, slugs []string) ([]SlugConflict, error) {
+	conflicts := []SlugConflict{}
+	if len(slugs) == 0 {
+		return conflicts, nil
+	}
+
+	var links []Link
+	if err := db.Select("id, title, slug").Where("slug IN ? AND id <> ?", slugs, linkID).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		conflicts = append(conflicts, SlugConflict{Slug: *link.Slug, Kind: "slug", LinkID: link.ID, LinkTitle: link.Title})
+	}
+
+	var aliases []struct {
+		Slug   string
+		LinkID uint
+		Title  string
+	}
+	err := db.Table("link_aliases").
+		Select("link_aliases.slug, link_aliases.link_id, links.title").
+		Joins("JOIN links ON links.id = link_aliases.link_id").
+		Where("link_aliases.slug IN ? AND link_aliases.link_id <> ?", slugs, linkID).
+		Scan(&aliases).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range aliases {
+		conflicts = append(conflicts, SlugConflict{Slug: a.Slug, Kind: "alias", LinkID: a.LinkID, LinkTitle: a.Title})
+	}
+	return conflicts, nil
+}
+
+// FindLinkBySlug 按短链接名或别名查找链接，找不到时返回 gorm.ErrRecordNotFound
+func FindLinkBySlug(db *gorm.DB, slug string) (*Link, error) {
+	slug = NormalizeSlug(slug)
+	var link Link
+	err := db.Where("slug = ?", slug).First(&link).Error
+	if err == gorm.ErrRecordNotFound {
+		err = db.Where("id = (SELECT link_id FROM link_aliases WHERE slug = ?)", slug).First(&link).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
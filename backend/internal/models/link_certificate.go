@@ -0,0 +1,34 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// LinkCertificate 链接的 TLS 证书信息（嵌入 Link，列名前缀 cert_）
+type LinkCertificate struct {
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Issuer        string     `gorm:"size:255" json:"issuer"`
+	Subject       string     `gorm:"size:255" json:"subject"`
+	HostnameMatch *bool      `json:"hostname_match"` // 证书 SAN 是否匹配链接主机名
+	CheckedAt     *time.Time `json:"checked_at"`
+}
+
+// DaysRemaining 距离证书过期的天数（已过期时为负数），没有证书信息时返回 nil
+func (c *LinkCertificate) DaysRemaining(now time.Time) *int {
+	if c.ExpiresAt == nil {
+		return nil
+	}
+	days := int(math.Floor(c.ExpiresAt.Sub(now).Hours() / 24))
+	return &days
+}
+
+// IsExpiring 证书是否会在 days 天内过期（包括已过期）
+func (c *LinkCertificate) IsExpiring(now time.Time, days int) bool {
+	return c.ExpiresAt != nil && c.ExpiresAt.Before(now.AddDate(0, 0, days))
+}
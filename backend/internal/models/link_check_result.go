@@ -0,0 +1,34 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"time"
+)
+
+// LinkCheckResult 链接检测结果（健康历史）
+type LinkCheckResult struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	LinkID     uint      `gorm:"not null;index:idx_check_results_link_time,priority:1" json:"link_id"`
+	Status     string    `gorm:"not null;size:20" json:"status"` // active | error
+	StatusCode int       `gorm:"not null;default:0" json:"status_code"`
+	LatencyMs  int64     `gorm:"not null;default:0" json:"latency_ms"`
+	ErrorClass string    `gorm:"size:50" json:"error_class,omitempty"`
+	Error      string    `gorm:"type:text" json:"error,omitempty"`
+	FinalURL   string    `gorm:"type:text" json:"final_url,omitempty"` // 发生重定向时的最终地址
+	Source     string    `gorm:"not null;default:'scheduler';size:20" json:"source"` // scheduler | manual | agent
+	CheckedAt  time.Time `gorm:"not null;index;index:idx_check_results_link_time,priority:2" json:"checked_at"`
+}
+
+// TableName 指定表名
+func (LinkCheckResult) TableName() string {
+	return "link_check_results"
+}
+
+// IsUp 判断本次检测是否成功
+func (r *LinkCheckResult) IsUp() bool {
+	return r.Status == "active"
+}
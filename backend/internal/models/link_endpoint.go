@@ -0,0 +1,167 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxLinkEndpoints 每个链接最多的环境地址数
+const MaxLinkEndpoints = 20
+
+// environmentPattern 环境名称：小写字母、数字、下划线和短横线
+var environmentPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// LinkEndpoint 链接在某个环境（prod / staging / dev 等）下的访问地址，每个地址单独检测
+type LinkEndpoint struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	LinkID         uint       `gorm:"not null;uniqueIndex:idx_link_endpoints_env,priority:1" json:"link_id"`
+	Environment    string     `gorm:"not null;size:50;uniqueIndex:idx_link_endpoints_env,priority:2" json:"environment"`
+	URL            string     `gorm:"not null;type:text" json:"url"`
+	IsDefault      bool       `gorm:"not null;default:false" json:"is_default"` // 默认环境，链接的 URL 与其保持一致
+	SortOrder      int        `gorm:"not null;default:0" json:"sort_order"`
+	Status         string     `gorm:"not null;default:'';size:20" json:"status"` // active | error，尚未检测时为空
+	LastCheckedAt  *time.Time `gorm:"type:timestamp" json:"last_checked_at"`
+	LastStatusCode int        `gorm:"not null;default:0" json:"-"` // 检测诊断信息只在管理后台（AdminEndpoint）返回
+	LastLatencyMs  int64      `gorm:"not null;default:0" json:"-"`
+	LastErrorClass string     `gorm:"size:50" json:"-"`
+	LastError      string     `gorm:"type:text" json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LinkEndpoint) TableName() string {
+	return "link_endpoints"
+}
+
+// NormalizeEnvironment 环境名称去掉首尾空白并转小写
+func NormalizeEnvironment(env string) string {
+	return strings.ToLower(strings.TrimSpace(env))
+}
+
+// ValidateEnvironment 校验环境名称
+func ValidateEnvironment(env string) error {
+	if !environmentPattern.MatchString(env) {
+		return fmt.Errorf("invalid environment %q: use lowercase letters, digits, '_' or '-' (max 50)", env)
+	}
+	return nil
+}
+
+// PreloadEndpoints 按顺序预加载链接的环境地址，用于 Scopes
+func PreloadEndpoints(db *gorm.DB) *gorm.DB {
+	return db.Preload("Endpoints", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("sort_order, id")
+	})
+}
+
+// SetEndpoints 规范化并校验环境地址，按顺序编号；有默认环境时链接的 URL 改为默认环境的地址
+func (l *Link) SetEndpoints(endpoints []LinkEndpoint) error {
+	if len(endpoints) > MaxLinkEndpoints {
+		return fmt.Errorf("a link can have at most %d endpoints", MaxLinkEndpoints)
+	}
+
+	seen := make(map[string]bool, len(endpoints))
+	var defaultURL string
+	for i := range endpoints {
+		e := &endpoints[i]
+		e.Environment = NormalizeEnvironment(e.Environment)
+		if err := ValidateEnvironment(e.Environment); err != nil {
+			return err
+		}
+		if seen[e.Environment] {
+			return fmt.Errorf("duplicate environment %q", e.Environment)
+		}
+		seen[e.Environment] = true
+
+		u, err := normalizeEndpointURL(e.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL for environment %q: %w", e.Environment, err)
+		}
+		e.URL = u
+		e.SortOrder = i
+
+		if e.IsDefault {
+			if defaultURL != "" {
+				return fmt.Errorf("only one endpoint can be the default")
+			}
+			defaultURL = e.URL
+		}
+	}
+
+	l.Endpoints = endpoints
+	if defaultURL != "" {
+		l.URL = defaultURL
+	}
+	return nil
+}
+
+// Endpoint 按环境名称查找环境地址，找不到时返回 nil
+func (l *Link) Endpoint(env string) *LinkEndpoint {
+	env = NormalizeEnvironment(env)
+	for i := range l.Endpoints {
+		if l.Endpoints[i].Environment == env {
+			return &l.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// DefaultEndpoint 默认环境地址，没有时返回 nil
+func (l *Link) DefaultEndpoint() *LinkEndpoint {
+	for i := range l.Endpoints {
+		if l.Endpoints[i].IsDefault {
+			return &l.Endpoints[i]
+		}
+	}
+	return nil
+}
+
+// ResolveEndpoint 选择跳转地址：指定的环境 > 用户偏好的环境 > 默认环境 > 链接 URL。
+// 返回地址和环境名称（使用链接 URL 时环境为空）；指定的环境不存在时 ok 为 false。
+func (l *Link) ResolveEndpoint(env, preferred string) (target, environment string, ok bool) {
+	if env != "" {
+		e := l.Endpoint(env)
+		if e == nil {
+			return "", "", false
+		}
+		return e.URL, e.Environment, true
+	}
+	if preferred != "" {
+		if e := l.Endpoint(preferred); e != nil {
+			return e.URL, e.Environment, true
+		}
+	}
+	if e := l.DefaultEndpoint(); e != nil {
+		return e.URL, e.Environment, true
+	}
+	return l.URL, "", true
+}
+
+// normalizeEndpointURL 与链接 URL 相同的规范化：没有协议时补 https://
+func normalizeEndpointURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("URL is required")
+	}
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL must include a host")
+	}
+	return raw, nil
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import "time"
+
+// 图标来源
+const (
+	IconSourceFetched  = "fetched"  // 从网站自动获取，定时刷新
+	IconSourceUploaded = "uploaded" // 管理员上传，不会被自动刷新覆盖
+)
+
+// LinkIcon 链接图标，统一缩放后以 PNG 保存在数据库中。
+// 获取失败时 Data 为空并记录错误，前台使用生成的字母图标。
+type LinkIcon struct {
+	LinkID    uint       `gorm:"primaryKey;autoIncrement:false" json:"link_id"`
+	Data      []byte     `json:"-"`
+	Hash      string     `gorm:"size:64;not null;default:''" json:"hash"` // Data 的 SHA-256，为空表示没有图标
+	Source    string     `gorm:"size:20;not null" json:"source"`          // fetched | uploaded
+	SourceURL string     `gorm:"type:text" json:"source_url"`             // 图标的原始地址，上传时为空
+	FetchedAt *time.Time `gorm:"type:timestamp;index" json:"fetched_at"`  // 最近一次获取（包括失败）的时间
+	LastError string     `gorm:"type:text" json:"last_error"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LinkIcon) TableName() string {
+	return "link_icons"
+}
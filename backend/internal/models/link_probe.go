@@ -0,0 +1,156 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinkProbe 链接探测配置（嵌入 Link，列名前缀 probe_）
+type LinkProbe struct {
+	Method          string            `gorm:"size:10" json:"method"`           // GET | HEAD，为空时使用 GET
+	ExpectedStatus  string            `gorm:"size:255" json:"expected_status"` // 如 "200-299,401"，为空时使用 200-399
+	Keyword         string            `gorm:"type:text" json:"keyword"`
+	KeywordRegex    bool              `gorm:"not null;default:false" json:"keyword_regex"`
+	KeywordAbsent   bool              `gorm:"not null;default:false" json:"keyword_absent"` // 为 true 时响应体不能匹配关键字
+	FollowRedirects *bool             `json:"follow_redirects"`                             // 为空时跟随重定向
+	Headers         map[string]string `gorm:"serializer:json;type:text" json:"headers"`
+	SkipTLSVerify   bool              `gorm:"not null;default:false" json:"skip_tls_verify"` // 跳过证书校验（自签名证书的内部服务）
+
+	// 状态阈值和重试，为 0 / 空时使用系统设置
+	FailureThreshold int  `gorm:"not null;default:0" json:"failure_threshold"` // 连续失败多少次标记为 error
+	SuccessThreshold int  `gorm:"not null;default:0" json:"success_threshold"` // 连续成功多少次从 error 恢复
+	Retries          *int `json:"retries"`                                     // 单次检测内的重试次数
+}
+
+// statusRange 状态码区间（闭区间）
+type statusRange struct {
+	from, to int
+}
+
+// HTTPMethod 返回探测使用的请求方法
+func (p *LinkProbe) HTTPMethod() string {
+	if strings.EqualFold(p.Method, http.MethodHead) {
+		return http.MethodHead
+	}
+	return http.MethodGet
+}
+
+// ShouldFollowRedirects 是否跟随重定向
+func (p *LinkProbe) ShouldFollowRedirects() bool {
+	return p.FollowRedirects == nil || *p.FollowRedirects
+}
+
+// AcceptsStatus 判断状态码是否符合预期
+func (p *LinkProbe) AcceptsStatus(code int) bool {
+	ranges, err := parseStatusRanges(p.ExpectedStatus)
+	if err != nil || len(ranges) == 0 {
+		return code >= 200 && code < 400
+	}
+	for _, r := range ranges {
+		if code >= r.from && code <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// KeywordMatcher 返回关键字匹配函数，未配置关键字时返回 nil
+func (p *LinkProbe) KeywordMatcher() (func([]byte) bool, error) {
+	if p.Keyword == "" {
+		return nil, nil
+	}
+	if p.KeywordRegex {
+		re, err := regexp.Compile(p.Keyword)
+		if err != nil {
+			return nil, err
+		}
+		return re.Match, nil
+	}
+	keyword := []byte(p.Keyword)
+	return func(body []byte) bool {
+		return bytes.Contains(body, keyword)
+	}, nil
+}
+
+// Validate 校验探测配置
+func (p *LinkProbe) Validate() error {
+	switch strings.ToUpper(p.Method) {
+	case "", http.MethodGet, http.MethodHead:
+	default:
+		return fmt.Errorf("probe method must be GET or HEAD")
+	}
+
+	if _, err := parseStatusRanges(p.ExpectedStatus); err != nil {
+		return err
+	}
+
+	if p.Keyword != "" && p.HTTPMethod() == http.MethodHead {
+		return fmt.Errorf("probe keyword requires GET method")
+	}
+	if _, err := p.KeywordMatcher(); err != nil {
+		return fmt.Errorf("invalid probe keyword regex: %w", err)
+	}
+
+	if p.FailureThreshold < 0 || p.SuccessThreshold < 0 {
+		return fmt.Errorf("probe thresholds cannot be negative")
+	}
+	if p.Retries != nil && (*p.Retries < 0 || *p.Retries > 5) {
+		return fmt.Errorf("probe retries must be between 0 and 5")
+	}
+
+	for name := range p.Headers {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("probe header name cannot be empty")
+		}
+	}
+	return nil
+}
+
+// Normalize 规范化探测配置
+func (p *LinkProbe) Normalize() {
+	p.Method = strings.ToUpper(strings.TrimSpace(p.Method))
+	p.ExpectedStatus = strings.ReplaceAll(strings.TrimSpace(p.ExpectedStatus), " ", "")
+}
+
+// parseStatusRanges 解析状态码列表，如 "200-299,401,403"
+func parseStatusRanges(s string) ([]statusRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var ranges []statusRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected status: %s", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("invalid expected status: %s", part)
+			}
+		}
+
+		if start < 100 || end > 599 || start > end {
+			return nil, fmt.Errorf("invalid expected status: %s", part)
+		}
+		ranges = append(ranges, statusRange{from: start, to: end})
+	}
+	return ranges, nil
+}
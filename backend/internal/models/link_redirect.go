@@ -0,0 +1,38 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// RedirectHop 重定向链中的一跳
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// LinkRedirect 最近一次检测的重定向信息（嵌入 Link，列名前缀 redirect_）
+type LinkRedirect struct {
+	FinalURL  string        `gorm:"type:text" json:"final_url"` // 重定向后的最终地址，没有重定向时为空
+	Chain     []RedirectHop `gorm:"serializer:json;type:text" json:"chain"`
+	Permanent bool          `gorm:"not null;default:false;index" json:"permanent"` // 整条链都是 301/308
+	CheckedAt *time.Time    `json:"checked_at"`
+}
+
+// IsPermanentChain 判断重定向链是否全部为永久重定向
+func IsPermanentChain(chain []RedirectHop) bool {
+	if len(chain) == 0 {
+		return false
+	}
+	for _, hop := range chain {
+		if hop.StatusCode != http.StatusMovedPermanently && hop.StatusCode != http.StatusPermanentRedirect {
+			return false
+		}
+	}
+	return true
+}
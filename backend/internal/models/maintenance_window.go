@@ -0,0 +1,225 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 维护窗口内的检测方式
+const (
+	MaintenanceSkip   = "skip"   // 不检测
+	MaintenanceFreeze = "freeze" // 照常检测并记录历史，但不改变链接状态、不发送通知
+)
+
+// maxRecurringDuration 周期性维护窗口的最大时长
+const maxRecurringDuration = 7 * 24 * 60
+
+// MaintenanceWindow 维护窗口：计划内的升级、停机期间不检测或冻结链接状态，前台显示维护提示
+type MaintenanceWindow struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Name    string `gorm:"not null;size:255" json:"name"`
+	Message string `gorm:"type:text" json:"message"`     // 前台显示的维护说明
+	Mode    string `gorm:"not null;size:20" json:"mode"` // skip | freeze
+	Enabled bool   `gorm:"not null" json:"enabled"`
+
+	// 一次性窗口：StartsAt ~ EndsAt
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	// 周期性窗口：每周 Weekdays（0 为周日，为空表示每天）的 StartTime 开始，持续 DurationMinutes 分钟
+	Weekdays        []int  `gorm:"serializer:json;type:text" json:"weekdays"`
+	StartTime       string `gorm:"size:5" json:"start_time"` // HH:MM
+	DurationMinutes int    `gorm:"not null;default:0" json:"duration_minutes"`
+	Timezone        string `gorm:"size:64" json:"timezone"` // 如 Asia/Shanghai，为空时使用服务器时区
+
+	// 范围：链接、分类或标签，满足任一即生效
+	LinkIDs     []uint `gorm:"serializer:json;type:text" json:"link_ids"`
+	CategoryIDs []uint `gorm:"serializer:json;type:text" json:"category_ids"`
+	TagIDs      []uint `gorm:"serializer:json;type:text" json:"tag_ids"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+// IsRecurring 是否为周期性窗口
+func (w *MaintenanceWindow) IsRecurring() bool {
+	return w.StartTime != ""
+}
+
+// Validate 校验维护窗口配置
+func (w *MaintenanceWindow) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if w.Mode != MaintenanceSkip && w.Mode != MaintenanceFreeze {
+		return fmt.Errorf("invalid mode %q: use skip or freeze", w.Mode)
+	}
+	if len(w.LinkIDs) == 0 && len(w.CategoryIDs) == 0 && len(w.TagIDs) == 0 {
+		return fmt.Errorf("at least one link, category or tag is required")
+	}
+
+	if !w.IsRecurring() {
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return fmt.Errorf("starts_at and ends_at are required (or start_time for a recurring window)")
+		}
+		if !w.EndsAt.After(*w.StartsAt) {
+			return fmt.Errorf("ends_at must be after starts_at")
+		}
+		return nil
+	}
+
+	if _, err := time.Parse("15:04", w.StartTime); err != nil {
+		return fmt.Errorf("invalid start_time %q: use HH:MM", w.StartTime)
+	}
+	if w.DurationMinutes <= 0 || w.DurationMinutes > maxRecurringDuration {
+		return fmt.Errorf("duration_minutes must be between 1 and %d", maxRecurringDuration)
+	}
+	for _, day := range w.Weekdays {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("invalid weekday %d: use 0 (Sunday) to 6 (Saturday)", day)
+		}
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", w.Timezone)
+	}
+	return nil
+}
+
+// ActiveAt 判断窗口在 now 时是否生效
+func (w *MaintenanceWindow) ActiveAt(now time.Time) bool {
+	if !w.Enabled {
+		return false
+	}
+	if !w.IsRecurring() {
+		return w.StartsAt != nil && w.EndsAt != nil && !now.Before(*w.StartsAt) && now.Before(*w.EndsAt)
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return false
+	}
+	clock, err := time.Parse("15:04", w.StartTime)
+	if err != nil {
+		return false
+	}
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+
+	// 从今天往前找，覆盖跨天的窗口
+	local := now.In(loc)
+	for offset := 0; offset <= w.DurationMinutes/(24*60)+1; offset++ {
+		day := local.AddDate(0, 0, -offset)
+		if !w.onWeekday(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if !now.Before(start) && now.Before(start.Add(duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// onWeekday 周期性窗口是否在该星期几开始
+func (w *MaintenanceWindow) onWeekday(day time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceIndex 当前生效的维护窗口，按链接索引
+type MaintenanceIndex map[uint]*MaintenanceWindow
+
+// For 返回覆盖该链接的维护窗口，没有时返回 nil
+func (m MaintenanceIndex) For(linkID uint) *MaintenanceWindow {
+	return m[linkID]
+}
+
+// Unaffected 不在任何维护窗口内的链接
+func (m MaintenanceIndex) Unaffected(links []Link) []Link {
+	if len(m) == 0 {
+		return links
+	}
+	out := make([]Link, 0, len(links))
+	for _, link := range links {
+		if m[link.ID] == nil {
+			out = append(out, link)
+		}
+	}
+	return out
+}
+
+// ActiveMaintenance 查询 now 时生效的维护窗口并展开到链接。
+// 一个链接被多个窗口覆盖时 skip 优先于 freeze。
+func ActiveMaintenance(db *gorm.DB, now time.Time) (MaintenanceIndex, error) {
+	var windows []MaintenanceWindow
+	if err := db.Where("enabled = ?", true).Find(&windows).Error; err != nil {
+		return nil, err
+	}
+
+	index := MaintenanceIndex{}
+	for i := range windows {
+		w := &windows[i]
+		if !w.ActiveAt(now) {
+			continue
+		}
+
+		ids := append([]uint{}, w.LinkIDs...)
+		if len(w.CategoryIDs) > 0 {
+			var byCategory []uint
+			if err := db.Model(&Link{}).Where("category_id IN ?", w.CategoryIDs).Pluck("id", &byCategory).Error; err != nil {
+				return nil, err
+			}
+			ids = append(ids, byCategory...)
+		}
+		if len(w.TagIDs) > 0 {
+			var byTag []uint
+			if err := db.Table("link_tags").Where("tag_id IN ?", w.TagIDs).Pluck("link_id", &byTag).Error; err != nil {
+				return nil, err
+			}
+			ids = append(ids, byTag...)
+		}
+
+		for _, id := range ids {
+			if current, ok := index[id]; !ok || (current.Mode == MaintenanceFreeze && w.Mode == MaintenanceSkip) {
+				index[id] = w
+			}
+		}
+	}
+	return index, nil
+}
+
+// MarkMaintenance 填充链接的维护标记（前台展示用）
+func MarkMaintenance(db *gorm.DB, links []Link) error {
+	if len(links) == 0 {
+		return nil
+	}
+	index, err := ActiveMaintenance(db, time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range links {
+		if w := index.For(links[i].ID); w != nil {
+			links[i].Maintenance = true
+			links[i].MaintenanceMessage = w.Message
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 通知渠道类型
+const (
+	ChannelDingTalk = "dingtalk"
+	ChannelFeishu   = "feishu"
+	ChannelWeCom    = "wecom"
+	ChannelSlack    = "slack"
+	ChannelEmail    = "email"
+)
+
+// NotificationChannel 通知渠道（链接状态变更时发送汇总通知）
+type NotificationChannel struct {
+	ID       uint               `gorm:"primaryKey" json:"id"`
+	Name     string             `gorm:"not null;size:255;unique" json:"name"`
+	Type     string             `gorm:"not null;size:20" json:"type"` // dingtalk | feishu | wecom | slack | email
+	Enabled  bool               `gorm:"not null" json:"enabled"`
+	Config   NotificationConfig `gorm:"serializer:json;type:text" json:"config"`
+	Template string             `gorm:"type:text" json:"template"` // text/template 模板，为空时使用默认模板
+
+	// 通知的事件
+	NotifyDown     bool `gorm:"not null" json:"notify_down"`
+	NotifyRecovery bool `gorm:"not null" json:"notify_recovery"`
+
+	// 路由规则：链接属于任一分类或带有任一标签时通知，都为空时通知所有链接
+	CategoryIDs []uint `gorm:"serializer:json;type:text" json:"category_ids"`
+	TagIDs      []uint `gorm:"serializer:json;type:text" json:"tag_ids"`
+
+	LastSentAt *time.Time `json:"last_sent_at"`
+	LastError  string     `gorm:"type:text" json:"last_error"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NotificationConfig 渠道配置
+type NotificationConfig struct {
+	// 机器人 / Webhook（dingtalk、feishu、wecom、slack）
+	WebhookURL string `json:"webhook_url,omitempty"`
+	Secret     string `json:"secret,omitempty"` // 钉钉、飞书加签密钥
+
+	// SMTP（email）
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"` // 465 使用 TLS，其他端口支持 STARTTLS
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// MaskedSecret 接口输出中代替密钥和密码的占位值
+const MaskedSecret = "******"
+
+// TableName 指定表名
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// Masked 返回隐藏了加签密钥和 SMTP 密码的副本，用于接口输出
+func (n NotificationChannel) Masked() NotificationChannel {
+	if n.Config.Secret != "" {
+		n.Config.Secret = MaskedSecret
+	}
+	if n.Config.Password != "" {
+		n.Config.Password = MaskedSecret
+	}
+	return n
+}
+
+// KeepSecrets 密钥或密码为空或为占位值时沿用 old 中的值，
+// 使客户端提交接口返回的（已隐藏的）配置时不会覆盖已保存的密钥
+func (c *NotificationConfig) KeepSecrets(old NotificationConfig) {
+	if c.Secret == "" || c.Secret == MaskedSecret {
+		c.Secret = old.Secret
+	}
+	if c.Password == "" || c.Password == MaskedSecret {
+		c.Password = old.Password
+	}
+}
+
+// Validate 校验渠道配置
+func (n *NotificationChannel) Validate() error {
+	if strings.TrimSpace(n.Name) == "" {
+		return fmt.Errorf("channel name is required")
+	}
+
+	switch n.Type {
+	case ChannelDingTalk, ChannelFeishu, ChannelWeCom, ChannelSlack:
+		u, err := url.Parse(n.Config.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url")
+		}
+	case ChannelEmail:
+		if n.Config.SMTPHost == "" {
+			return fmt.Errorf("smtp host is required")
+		}
+		if n.Config.SMTPPort < 0 || n.Config.SMTPPort > 65535 {
+			return fmt.Errorf("invalid smtp port")
+		}
+		if _, err := mail.ParseAddress(n.Config.From); err != nil {
+			return fmt.Errorf("invalid sender address: %s", n.Config.From)
+		}
+		if len(n.Config.To) == 0 {
+			return fmt.Errorf("at least one recipient is required")
+		}
+		for _, to := range n.Config.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("invalid recipient address: %s", to)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported channel type: %s", n.Type)
+	}
+
+	if n.Template != "" {
+		if _, err := template.New(n.Name).Parse(n.Template); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	return nil
+}
+
+// Matches 判断链接是否命中路由规则
+func (n *NotificationChannel) Matches(categoryID uint, tagIDs []uint) bool {
+	if len(n.CategoryIDs) == 0 && len(n.TagIDs) == 0 {
+		return true
+	}
+	for _, id := range n.CategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	for _, id := range n.TagIDs {
+		for _, tagID := range tagIDs {
+			if id == tagID {
+				return true
+			}
+		}
+	}
+	return false
+}
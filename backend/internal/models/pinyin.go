@@ -0,0 +1,100 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"gorm.io/gorm"
+)
+
+// maxPinyinRun 单段连续汉字参与拼音索引的最大字数
+const maxPinyinRun = 32
+
+// pinyinArgs 不带声调的全拼，多音字取常用读音
+var pinyinArgs = pinyin.NewArgs()
+
+// Pinyin 计算文本中汉字的拼音检索词（以空格分隔）。
+// 每段连续汉字生成从每个字开始的全拼和首字母组合，这样输入拼音前缀或中间的词都能命中，
+// 如 "监控工具" -> "jiankonggongju konggongju gongju ju jkgj kgj gj j"。
+func Pinyin(text string) string {
+	runs := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.Is(unicode.Han, r)
+	})
+
+	var full, initials []string
+	for _, run := range runs {
+		syllables := pinyin.LazyPinyin(run, pinyinArgs)
+		if len(syllables) > maxPinyinRun {
+			syllables = syllables[:maxPinyinRun]
+		}
+		letters := make([]string, 0, len(syllables))
+		for _, s := range syllables {
+			letters = append(letters, s[:1])
+		}
+		for i := range syllables {
+			full = append(full, strings.Join(syllables[i:], ""))
+			initials = append(initials, strings.Join(letters[i:], ""))
+		}
+	}
+	return strings.Join(append(full, initials...), " ")
+}
+
+// Syllables 逐字返回文本的拼音，非汉字为空字符串
+func Syllables(text string) []string {
+	runes := []rune(text)
+	syllables := make([]string, len(runes))
+	for i, r := range runes {
+		if unicode.Is(unicode.Han, r) {
+			if s := pinyin.SinglePinyin(r, pinyinArgs); len(s) > 0 {
+				syllables[i] = s[0]
+			}
+		}
+	}
+	return syllables
+}
+
+// FillPinyin 补全缺少拼音检索词的链接标题、分类名和标签名（升级前创建的数据）
+func FillPinyin(db *gorm.DB) error {
+	var links []Link
+	if err := db.Select("id, title").Where("title_pinyin = ?", "").Find(&links).Error; err != nil {
+		return err
+	}
+	for _, link := range links {
+		if p := Pinyin(link.Title); p != "" {
+			if err := db.Model(&link).UpdateColumn("title_pinyin", p).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var categories []Category
+	if err := db.Select("id, name").Where("name_pinyin = ?", "").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if p := Pinyin(category.Name); p != "" {
+			if err := db.Model(&category).UpdateColumn("name_pinyin", p).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var tags []Tag
+	if err := db.Select("id, name").Where("name_pinyin = ?", "").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if p := Pinyin(tag.Name); p != "" {
+			if err := db.Model(&tag).UpdateColumn("name_pinyin", p).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
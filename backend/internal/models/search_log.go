@@ -0,0 +1,28 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"time"
+)
+
+// SearchLog 搜索记录（搜索分析），受 enable_analytics 设置控制
+type SearchLog struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Query         string     `gorm:"not null;size:255;index" json:"query"` // 规范化后的搜索语句（去掉首尾空白、转小写）
+	UserID        *uint      `gorm:"index" json:"user_id,omitempty"`
+	IPAddress     string     `gorm:"not null;size:45" json:"ip_address"`
+	Source        string     `gorm:"not null;size:20" json:"source"` // api | home | web
+	ResultCount   int        `gorm:"not null;default:0" json:"result_count"`
+	ClickedLinkID *uint      `json:"clicked_link_id,omitempty"` // 搜索后点击的第一个链接
+	ClickedAt     *time.Time `json:"clicked_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (SearchLog) TableName() string {
+	return "search_logs"
+}
@@ -6,6 +6,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -33,8 +34,48 @@ var DefaultSettings = map[string]string{
 	"enable_registration": "true",
 	"enable_link_check":   "true",
 	"check_interval_hours": "24",
+	"check_history_days":   "30",
+	"cert_warning_days":    "14",
+	"check_degraded_threshold": "2",
+	"check_failure_threshold": "2",
+	"check_success_threshold": "1",
+	"check_retries":           "1",
+	"check_retry_backoff_ms":  "1000",
+	"enable_status_page":        "true",
+	"status_page_cache_seconds": "30",
 	"links_per_page":      "12",
 	"enable_analytics":    "true",
+	"search_log_days":     "90",
 	"enable_pwa":          "true",
+	"enable_favicon_fetch": "true",
+	"favicon_refresh_days": "7",
 }
 
+
+// GetSetting 读取设置值，数据库中不存在时返回默认值
+func GetSetting(key string) string {
+	db := GetDB()
+	if db != nil {
+		var setting Setting
+		if err := db.Where("key = ?", key).First(&setting).Error; err == nil {
+			return setting.Value
+		}
+	}
+	return DefaultSettings[key]
+}
+
+// GetSettingInt 读取整数设置，无法解析时返回 defaultValue
+func GetSettingInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(GetSetting(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetSettingBool 读取布尔设置，无法解析时返回 defaultValue
+func GetSettingBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(GetSetting(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -15,11 +15,12 @@ import (
 
 // Tag 标签模型
 type Tag struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"uniqueIndex;not null;size:100" json:"name" binding:"required,min=1,max=100"`
-	Color     string    `gorm:"not null;size:7" json:"color" binding:"required"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"uniqueIndex;not null;size:100" json:"name" binding:"required,min=1,max=100"`
+	NamePinyin string    `gorm:"type:text;not null;default:''" json:"-"` // 名称的拼音检索词，保存时计算
+	Color      string    `gorm:"not null;size:7" json:"color" binding:"required"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 
 	// 关联
 	Links []Link `gorm:"many2many:link_tags;" json:"links,omitempty"`
@@ -37,12 +38,20 @@ func (t *Tag) LinksCount() int64 {
 	if db != nil {
 		db.Model(&Link{}).
 			Joins("JOIN link_tags ON link_tags.link_id = links.id").
-			Where("link_tags.tag_id = ? AND links.status = ?", t.ID, "active").
+			Where("link_tags.tag_id = ? AND links.status IN ?", t.ID, VisibleLinkStatuses).
 			Count(&count)
 	}
 	return count
 }
 
+// BeforeSave 保存前钩子
+func (t *Tag) BeforeSave(tx *gorm.DB) error {
+	if t.Name != "" {
+		t.NamePinyin = Pinyin(t.Name)
+	}
+	return nil
+}
+
 // BeforeCreate 创建前钩子
 func (t *Tag) BeforeCreate(tx *gorm.DB) error {
 	// 如果没有设置颜色，自动生成随机颜色
@@ -0,0 +1,225 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// URL 模板限制
+const (
+	MaxURLVariables     = 10  // 每个链接最多的模板变量数
+	maxURLVariableValue = 256 // 变量值的最大长度
+)
+
+var (
+	// placeholderPattern 模板中的变量引用 {name}
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// variableNamePattern 变量名：字母、数字和下划线，不以数字开头
+	variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,49}$`)
+	// hostValuePattern 主机名中的变量只能是字母、数字、点和短横线
+	hostValuePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+)
+
+// URLVariable URL 模板变量。链接 URL（以及各环境地址）中的 {name} 在点击时替换为变量值，
+// 检测时使用默认值。只替换已声明的变量，其他花括号原样保留。
+type URLVariable struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default"`
+	Values      []string `json:"values,omitempty"` // 允许的取值，为空时不限制
+}
+
+// IsTemplate 链接 URL 是否为模板
+func (l *Link) IsTemplate() bool {
+	return len(l.URLVariables) > 0
+}
+
+// Variable 按名称查找模板变量，找不到时返回 nil
+func (l *Link) Variable(name string) *URLVariable {
+	for i := range l.URLVariables {
+		if l.URLVariables[i].Name == name {
+			return &l.URLVariables[i]
+		}
+	}
+	return nil
+}
+
+// ValidateURLTemplate 校验模板变量，并确认使用默认值展开的链接 URL 和环境地址合法
+func (l *Link) ValidateURLTemplate() error {
+	if len(l.URLVariables) > MaxURLVariables {
+		return fmt.Errorf("a link can have at most %d URL variables", MaxURLVariables)
+	}
+	seen := make(map[string]bool, len(l.URLVariables))
+	for _, v := range l.URLVariables {
+		if !variableNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("invalid URL variable name %q: use letters, digits or '_' (max 50)", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate URL variable %q", v.Name)
+		}
+		seen[v.Name] = true
+		for _, value := range v.Values {
+			if err := checkVariableValue(v.Name, value); err != nil {
+				return err
+			}
+		}
+		if len(v.Values) > 0 && !slices.Contains(v.Values, v.Default) {
+			return fmt.Errorf("default value of URL variable %q must be one of its values", v.Name)
+		}
+	}
+	if !l.IsTemplate() {
+		return nil
+	}
+	if l.URL != "" {
+		if err := l.validateTemplate(l.URL); err != nil {
+			return err
+		}
+	}
+	for _, e := range l.Endpoints {
+		if err := l.validateTemplate(e.URL); err != nil {
+			return fmt.Errorf("endpoint %q: %w", e.Environment, err)
+		}
+	}
+	return nil
+}
+
+// validateTemplate 主机名中的变量必须声明允许的取值（否则点击时可以跳转到任意域名），且默认值能展开为合法 URL
+func (l *Link) validateTemplate(raw string) error {
+	hostStart, pathStart, _ := urlRegions(raw)
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(raw, -1) {
+		v := l.Variable(raw[m[2]:m[3]])
+		if v != nil && m[0] >= hostStart && m[0] < pathStart && len(v.Values) == 0 {
+			return fmt.Errorf("URL variable %q is used in the host and must declare its allowed values", v.Name)
+		}
+	}
+	_, err := l.ExpandURL(raw, nil)
+	return err
+}
+
+// DefaultURL 使用变量默认值生成的 URL，用于检测；不是模板时返回链接 URL
+func (l *Link) DefaultURL() string {
+	return l.DefaultURLFor(l.URL)
+}
+
+// DefaultURLFor 使用变量默认值展开指定模板（链接 URL 或环境地址），展开失败时原样返回
+func (l *Link) DefaultURLFor(raw string) string {
+	if !l.IsTemplate() {
+		return raw
+	}
+	expanded, err := l.ExpandURL(raw, nil)
+	if err != nil {
+		return raw
+	}
+	return expanded
+}
+
+// ExpandURL 用变量值展开模板，未提供的变量使用默认值。
+// 变量值按所在位置编码：路径中使用路径编码（不能插入 / 或 ..），查询参数和片段中使用查询编码，
+// 主机名中只允许字母、数字、点和短横线，且只接受声明的取值；不能出现在协议中。
+func (l *Link) ExpandURL(raw string, values map[string]string) (string, error) {
+	for name := range values {
+		if l.Variable(name) == nil {
+			return "", fmt.Errorf("unknown URL variable %q", name)
+		}
+	}
+	if !l.IsTemplate() {
+		return raw, nil
+	}
+
+	hostStart, pathStart, queryStart := urlRegions(raw)
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(raw, -1) {
+		v := l.Variable(raw[m[2]:m[3]])
+		if v == nil {
+			continue
+		}
+		value, ok := values[v.Name]
+		if !ok {
+			value = v.Default
+		}
+		if err := v.check(value); err != nil {
+			return "", err
+		}
+
+		var encoded string
+		switch {
+		case m[0] < hostStart:
+			return "", fmt.Errorf("URL variable %q cannot be used in the scheme", v.Name)
+		case m[0] < pathStart:
+			// 没有声明取值的旧模板只允许使用默认值，防止通过参数跳转到任意域名
+			if ok && len(v.Values) == 0 {
+				return "", fmt.Errorf("URL variable %q is used in the host and cannot be overridden", v.Name)
+			}
+			if !hostValuePattern.MatchString(value) {
+				return "", fmt.Errorf("invalid value for URL variable %q: only letters, digits, '.' and '-' are allowed in the host", v.Name)
+			}
+			encoded = value
+		case m[0] < queryStart:
+			if value == "." || value == ".." {
+				return "", fmt.Errorf("invalid value for URL variable %q", v.Name)
+			}
+			encoded = url.PathEscape(value)
+		default:
+			encoded = strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+		}
+		b.WriteString(raw[last:m[0]])
+		b.WriteString(encoded)
+		last = m[1]
+	}
+	b.WriteString(raw[last:])
+
+	expanded := b.String()
+	u, err := url.Parse(expanded)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL after expanding variables: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid URL after expanding variables: %s", expanded)
+	}
+	return expanded, nil
+}
+
+// check 校验变量值
+func (v *URLVariable) check(value string) error {
+	if len(v.Values) > 0 && !slices.Contains(v.Values, value) {
+		return fmt.Errorf("invalid value for URL variable %q: must be one of %s", v.Name, strings.Join(v.Values, ", "))
+	}
+	return checkVariableValue(v.Name, value)
+}
+
+// checkVariableValue 变量值不能过长或包含控制字符
+func checkVariableValue(name, value string) error {
+	if len(value) > maxURLVariableValue {
+		return fmt.Errorf("value of URL variable %q is too long (max %d bytes)", name, maxURLVariableValue)
+	}
+	if strings.ContainsFunc(value, unicode.IsControl) {
+		return fmt.Errorf("value of URL variable %q contains control characters", name)
+	}
+	return nil
+}
+
+// urlRegions 返回主机名、路径和查询参数（含片段）在模板中的起始位置
+func urlRegions(raw string) (hostStart, pathStart, queryStart int) {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		hostStart = i + 3
+	}
+	pathStart = len(raw)
+	if i := strings.IndexAny(raw[hostStart:], "/?#"); i >= 0 {
+		pathStart = hostStart + i
+	}
+	queryStart = len(raw)
+	if i := strings.IndexAny(raw[pathStart:], "?#"); i >= 0 {
+		queryStart = pathStart + i
+	}
+	return hostStart, pathStart, queryStart
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func templateLink() *Link {
+	return &Link{URLVariables: []URLVariable{
+		{Name: "region", Default: "cn", Values: []string{"cn", "us"}},
+		{Name: "project", Default: "ops"},
+		{Name: "q"},
+		{Name: "legacy", Default: "a"},
+	}}
+}
+
+func TestExpandURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		link    *Link
+		raw     string
+		values  map[string]string
+		want    string
+		wantErr string // 期望错误信息中包含的内容，为空表示不出错
+	}{
+		{"not a template", &Link{}, "https://example.com/{project}", nil, "https://example.com/{project}", ""},
+		{"not a template with values", &Link{}, "https://example.com", map[string]string{"project": "x"}, "", "unknown URL variable"},
+		{"defaults", templateLink(), "https://{region}.example.com/{project}?q={q}", nil, "https://cn.example.com/ops?q=", ""},
+		{
+			"values encoded by position", templateLink(), "https://{region}.example.com/{project}?q={q}",
+			map[string]string{"region": "us", "project": "a b", "q": "x y&z"},
+			"https://us.example.com/a%20b?q=x%20y%26z", "",
+		},
+		{"slash escaped in path", templateLink(), "https://example.com/{project}", map[string]string{"project": "a/b"}, "https://example.com/a%2Fb", ""},
+		{"fragment uses query encoding", templateLink(), "https://example.com/#/{project}", map[string]string{"project": "a/b"}, "https://example.com/#/a%2Fb", ""},
+		{"undeclared placeholder kept", templateLink(), "https://example.com/{other}/{project}", nil, "https://example.com/{other}/ops", ""},
+		{"dot dot in path", templateLink(), "https://example.com/{project}", map[string]string{"project": ".."}, "", "invalid value"},
+		{"value not allowed", templateLink(), "https://{region}.example.com", map[string]string{"region": "eu"}, "", "must be one of"},
+		{"unknown variable", templateLink(), "https://example.com", map[string]string{"foo": "x"}, "", "unknown URL variable"},
+		{"control characters", templateLink(), "https://example.com/{project}", map[string]string{"project": "a\nb"}, "", "control characters"},
+		{"variable in scheme", templateLink(), "{project}://example.com", nil, "", "scheme"},
+		{"host variable without values uses default", templateLink(), "https://{legacy}.example.com", nil, "https://a.example.com", ""},
+		{"host variable without values overridden", templateLink(), "https://{legacy}.example.com", map[string]string{"legacy": "evil.com"}, "", "cannot be overridden"},
+		{
+			"invalid host value", &Link{URLVariables: []URLVariable{{Name: "h", Default: "a_b", Values: []string{"a_b"}}}},
+			"https://{h}.example.com", nil, "", "only letters",
+		},
+		{"empty host after expansion", &Link{URLVariables: []URLVariable{{Name: "h"}}}, "https://{h}/", nil, "", "only letters"},
+	}
+	for _, tt := range tests {
+		got, err := tt.link.ExpandURL(tt.raw, tt.values)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: ExpandURL(%q) error = %v, want error containing %q", tt.name, tt.raw, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: ExpandURL(%q) error: %v", tt.name, tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: ExpandURL(%q) = %q, want %q", tt.name, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestValidateURLTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		vars    []URLVariable
+		wantErr string
+	}{
+		{"plain url", "https://example.com", nil, ""},
+		{"host variable with values", "https://{env}.example.com", []URLVariable{{Name: "env", Default: "prod", Values: []string{"prod", "test"}}}, ""},
+		{"host variable without values", "https://{env}.example.com", []URLVariable{{Name: "env", Default: "prod"}}, "must declare its allowed values"},
+		{"path variable without values", "https://example.com/{env}", []URLVariable{{Name: "env", Default: "prod"}}, ""},
+		{"default not in values", "https://example.com/{env}", []URLVariable{{Name: "env", Default: "dev", Values: []string{"prod"}}}, "must be one of its values"},
+		{"invalid name", "https://example.com/{1env}", []URLVariable{{Name: "1env"}}, "invalid URL variable name"},
+		{"duplicate name", "https://example.com/{env}", []URLVariable{{Name: "env"}, {Name: "env"}}, "duplicate"},
+	}
+	for _, tt := range tests {
+		link := &Link{URL: tt.url, URLVariables: tt.vars}
+		err := link.ValidateURLTemplate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
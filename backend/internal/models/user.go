@@ -13,18 +13,19 @@ import (
 
 // User 用户模型
 type User struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	Email        string    `gorm:"uniqueIndex;not null;size:255" json:"email" binding:"required,email"`
-	Username     string    `gorm:"uniqueIndex;not null;size:100" json:"username" binding:"required,min=3,max=100"`
-	PasswordHash string    `gorm:"not null;size:255" json:"-"`
-	Role         string    `gorm:"not null;default:'user';size:20" json:"role"` // user | admin
-	Active       bool      `gorm:"not null;default:true" json:"active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                   uint      `gorm:"primaryKey" json:"id"`
+	Email                string    `gorm:"uniqueIndex;not null;size:255" json:"email" binding:"required,email"`
+	Username             string    `gorm:"uniqueIndex;not null;size:100" json:"username" binding:"required,min=3,max=100"`
+	PasswordHash         string    `gorm:"not null;size:255" json:"-"`
+	Role                 string    `gorm:"not null;default:'user';size:20" json:"role"` // user | admin
+	Active               bool      `gorm:"not null;default:true" json:"active"`
+	PreferredEnvironment string    `gorm:"not null;default:'';size:50" json:"preferred_environment"` // 点击有多个环境的链接时优先跳转的环境
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 
 	// 关联
-	Favorites  []Favorite  `gorm:"foreignKey:UserID" json:"favorites,omitempty"`
-	ClickLogs  []ClickLog  `gorm:"foreignKey:UserID" json:"click_logs,omitempty"`
+	Favorites []Favorite `gorm:"foreignKey:UserID" json:"favorites,omitempty"`
+	ClickLogs []ClickLog `gorm:"foreignKey:UserID" json:"click_logs,omitempty"`
 }
 
 // TableName 指定表名
@@ -56,4 +57,3 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
-
@@ -0,0 +1,114 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 搜索来源
+const (
+	SourceAPI        = "api"        // 前台链接列表接口
+	SourceHome       = "home"       // 首页接口
+	SourceWeb        = "web"        // 服务端渲染页面
+	SourceOpenSearch = "opensearch" // 浏览器地址栏搜索（手气不错）
+)
+
+// 搜索分析参数
+const (
+	pruneInterval     = time.Hour // 清理过期搜索记录的间隔
+	maxLoggedQueryLen = 255
+)
+
+// LogQuery 记录一次搜索，返回搜索记录 ID。
+// 搜索语句为空、关闭了 enable_analytics 或写入失败时返回 0。
+func LogQuery(db *gorm.DB, q string, userID uint, ip, source string, resultCount int64) uint {
+	q = normalizeQuery(q)
+	if q == "" || !models.GetSettingBool("enable_analytics", true) {
+		return 0
+	}
+
+	entry := models.SearchLog{
+		Query:       q,
+		IPAddress:   ip,
+		Source:      source,
+		ResultCount: int(resultCount),
+	}
+	if userID != 0 {
+		entry.UserID = &userID
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		logger.Error("Failed to record search log", zap.Error(err))
+		return 0
+	}
+	return entry.ID
+}
+
+// LogClick 把链接点击记在搜索记录上，每条搜索只记录第一次点击。
+// 只有点击带上了搜索返回的 search_id 时才记录，不带时不归属于任何搜索。
+func LogClick(db *gorm.DB, searchID, linkID uint) {
+	if searchID == 0 || !models.GetSettingBool("enable_analytics", true) {
+		return
+	}
+
+	now := time.Now()
+	err := db.Model(&models.SearchLog{}).
+		Where("id = ? AND clicked_link_id IS NULL", searchID).
+		Updates(map[string]interface{}{"clicked_link_id": linkID, "clicked_at": now}).Error
+	if err != nil {
+		logger.Error("Failed to record search click", zap.Uint("search_id", searchID), zap.Error(err))
+	}
+}
+
+// normalizeQuery 合并空白、转小写并截断，便于按搜索语句聚合
+func normalizeQuery(q string) string {
+	q = strings.ToLower(strings.Join(strings.Fields(q), " "))
+	if runes := []rune(q); len(runes) > maxLoggedQueryLen {
+		q = string(runes[:maxLoggedQueryLen])
+	}
+	return q
+}
+
+// StartPruner 启动定时清理过期搜索记录的任务（启动时立即清理一次，之后每 pruneInterval 一次），ctx 取消时停止。
+// 清理不依赖新的搜索，也不受 enable_analytics 影响，关闭分析后已有的记录仍按 search_log_days 过期。
+func StartPruner(ctx context.Context, db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			pruneSearchLogs(db)
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// pruneSearchLogs 清理超过 search_log_days 天的搜索记录
+func pruneSearchLogs(db *gorm.DB) {
+	now := time.Now()
+	days := models.GetSettingInt("search_log_days", 90)
+	if days <= 0 {
+		return
+	}
+	result := db.Where("created_at < ?", now.AddDate(0, 0, -days)).Delete(&models.SearchLog{})
+	if result.Error != nil {
+		logger.Error("Failed to prune search logs", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Pruned search logs", zap.Int64("deleted", result.RowsAffected))
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"kk-nav/internal/models"
+)
+
+// Document 链接的索引文档，各字段保存切分后的索引词（以空格分隔）
+type Document struct {
+	LinkID      uint   `gorm:"primaryKey;autoIncrement:false"`
+	Title       string `gorm:"type:text;not null;default:''"` // 标题及其拼音
+	Tags        string `gorm:"type:text;not null;default:''"` // 标签名、分类名及其拼音
+	Description string `gorm:"type:text;not null;default:''"`
+	URL         string `gorm:"type:text;not null;default:''"`
+}
+
+// TableName 指定表名
+func (Document) TableName() string {
+	return "link_search_documents"
+}
+
+// NewDocument 生成链接的索引文档（需要预加载 Category 和 Tags）
+func NewDocument(link *models.Link) Document {
+	names := make([]string, 0, len(link.Tags)*2+2)
+	for _, tag := range link.Tags {
+		names = append(names, tag.Name, tag.NamePinyin)
+	}
+	names = append(names, link.Category.Name, link.Category.NamePinyin)
+
+	return Document{
+		LinkID:      link.ID,
+		Title:       indexText(link.Title, link.TitlePinyin),
+		Tags:        indexText(names...),
+		Description: indexText(link.Description),
+		URL:         indexText(link.URL),
+	}
+}
+
+// documents 批量生成索引文档
+func documents(links []models.Link) []Document {
+	docs := make([]Document, 0, len(links))
+	for i := range links {
+		docs = append(docs, NewDocument(&links[i]))
+	}
+	return docs
+}
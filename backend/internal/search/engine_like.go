@@ -0,0 +1,103 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeEngine 没有全文索引可用时的退化实现：在索引文档表上按词首 LIKE 匹配，
+// 按命中字段加权计分（标题 8、标签/分类 4、描述 2、URL 1）。
+type likeEngine struct{}
+
+// likeWeights 各列权重
+var likeWeights = []struct {
+	column string
+	weight int
+}{
+	{"title", 8},
+	{"tags", 4},
+	{"description", 2},
+	{"url", 1},
+}
+
+func (likeEngine) Name() string { return "like" }
+
+func (likeEngine) Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Document{})
+}
+
+func (likeEngine) Save(db *gorm.DB, docs []Document) error {
+	return saveDocuments(db, docs)
+}
+
+func (likeEngine) Delete(db *gorm.DB, linkIDs []uint) error {
+	return db.Where("link_id IN ?", linkIDs).Delete(&Document{}).Error
+}
+
+func (likeEngine) Clear(db *gorm.DB) error {
+	return db.Exec("DELETE FROM link_search_documents").Error
+}
+
+func (likeEngine) Match(db *gorm.DB, groups [][]string, limit int) ([]uint, error) {
+	query := db.Model(&Document{})
+
+	var score []string
+	var scoreVars []interface{}
+	for _, group := range groups {
+		sql, vars := likeGroup(group)
+		query = query.Where(sql, vars...)
+		for _, term := range group {
+			for _, w := range likeWeights {
+				score = append(score, "CASE WHEN "+w.column+" LIKE ? THEN "+strconv.Itoa(w.weight)+" ELSE 0 END")
+				scoreVars = append(scoreVars, likePattern(term))
+			}
+		}
+	}
+
+	var ids []uint
+	err := query.
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "(" + strings.Join(score, " + ") + ") DESC, link_id",
+			Vars: scoreVars,
+		}}).
+		Limit(limit).
+		Pluck("link_id", &ids).Error
+	return ids, err
+}
+
+func (likeEngine) MatchSQL(groups [][]string) (string, []interface{}) {
+	conds := make([]string, len(groups))
+	var vars []interface{}
+	for i, group := range groups {
+		sql, groupVars := likeGroup(group)
+		conds[i] = "(" + sql + ")"
+		vars = append(vars, groupVars...)
+	}
+	return "SELECT link_id FROM link_search_documents WHERE " + strings.Join(conds, " AND "), vars
+}
+
+// likeGroup 组内任一词在任一列中按词首命中
+func likeGroup(group []string) (string, []interface{}) {
+	var conds []string
+	var vars []interface{}
+	for _, term := range group {
+		for _, w := range likeWeights {
+			conds = append(conds, w.column+" LIKE ?")
+			vars = append(vars, likePattern(term))
+		}
+	}
+	return strings.Join(conds, " OR "), vars
+}
+
+// likePattern 索引词都以空格开头，查询词只包含字母和数字，不需要转义
+func likePattern(term string) string {
+	return "% " + term + "%"
+}
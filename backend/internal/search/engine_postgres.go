@@ -0,0 +1,91 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postgresEngine Postgres 全文搜索：加权的 tsvector 生成列 + GIN 索引，按 ts_rank 排序。
+// 索引词已经预先切分，使用 simple 配置避免词干化和停用词。
+type postgresEngine struct{}
+
+func (postgresEngine) Name() string { return "postgres" }
+
+func (postgresEngine) Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Document{}); err != nil {
+		return err
+	}
+	if err := db.Exec(`ALTER TABLE link_search_documents ADD COLUMN IF NOT EXISTS document tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', title), 'A') ||
+			setweight(to_tsvector('simple', tags), 'B') ||
+			setweight(to_tsvector('simple', description), 'C') ||
+			setweight(to_tsvector('simple', url), 'D')
+		) STORED`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_link_search_documents_document
+		ON link_search_documents USING GIN (document)`).Error
+}
+
+func (postgresEngine) Save(db *gorm.DB, docs []Document) error {
+	return saveDocuments(db, docs)
+}
+
+func (postgresEngine) Delete(db *gorm.DB, linkIDs []uint) error {
+	return db.Where("link_id IN ?", linkIDs).Delete(&Document{}).Error
+}
+
+func (postgresEngine) Clear(db *gorm.DB) error {
+	return db.Exec("DELETE FROM link_search_documents").Error
+}
+
+func (postgresEngine) Match(db *gorm.DB, groups [][]string, limit int) ([]uint, error) {
+	query := tsQuery(groups)
+
+	var ids []uint
+	err := db.Model(&Document{}).
+		Where("document @@ to_tsquery('simple', ?)", query).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(document, to_tsquery('simple', ?)) DESC, link_id",
+			Vars: []interface{}{query},
+		}}).
+		Limit(limit).
+		Pluck("link_id", &ids).Error
+	return ids, err
+}
+
+func (postgresEngine) MatchSQL(groups [][]string) (string, []interface{}) {
+	return "SELECT link_id FROM link_search_documents WHERE document @@ to_tsquery('simple', ?)", []interface{}{tsQuery(groups)}
+}
+
+// tsQuery 每个查询词按前缀匹配，组内任一命中、所有组都命中：('grafana':* | 'grafna':*) & '监控':*
+func tsQuery(groups [][]string) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		alternatives := make([]string, len(group))
+		for j, term := range group {
+			alternatives[j] = "'" + term + "':*"
+		}
+		parts[i] = "(" + strings.Join(alternatives, " | ") + ")"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// saveDocuments 写入索引文档，已存在时覆盖
+func saveDocuments(db *gorm.DB, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "link_id"}},
+		UpdateAll: true,
+	}).CreateInBatches(docs, 200).Error
+}
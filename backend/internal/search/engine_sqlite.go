@@ -0,0 +1,83 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// fts5Engine SQLite FTS5 全文搜索，按 bm25 加权排序（rowid 即链接 ID）。
+// mattn/go-sqlite3 需要使用 -tags sqlite_fts5 构建才包含 FTS5 模块。
+type fts5Engine struct{}
+
+// bm25 各列权重：标题 > 标签/分类 > 描述 > URL
+const fts5Rank = "bm25(link_search_fts, 10.0, 5.0, 2.0, 1.0)"
+
+func (fts5Engine) Name() string { return "fts5" }
+
+func (fts5Engine) Migrate(db *gorm.DB) error {
+	if err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS link_search_fts
+		USING fts5(title, tags, description, url, tokenize = 'unicode61')`).Error; err != nil {
+		return err
+	}
+	// 表已存在时 CREATE 不会加载模块，查询一次确认当前构建包含 FTS5
+	var count int64
+	return db.Raw("SELECT COUNT(*) FROM link_search_fts").Scan(&count).Error
+}
+
+func (e fts5Engine) Save(db *gorm.DB, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.LinkID
+	}
+	if err := e.Delete(db, ids); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := db.Exec("INSERT INTO link_search_fts (rowid, title, tags, description, url) VALUES (?, ?, ?, ?, ?)",
+			doc.LinkID, doc.Title, doc.Tags, doc.Description, doc.URL).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fts5Engine) Delete(db *gorm.DB, linkIDs []uint) error {
+	return db.Exec("DELETE FROM link_search_fts WHERE rowid IN ?", linkIDs).Error
+}
+
+func (fts5Engine) Clear(db *gorm.DB) error {
+	return db.Exec("DELETE FROM link_search_fts").Error
+}
+
+func (fts5Engine) Match(db *gorm.DB, groups [][]string, limit int) ([]uint, error) {
+	var ids []uint
+	err := db.Raw("SELECT rowid FROM link_search_fts WHERE link_search_fts MATCH ? ORDER BY "+fts5Rank+", rowid LIMIT ?",
+		fts5Query(groups), limit).Scan(&ids).Error
+	return ids, err
+}
+
+func (fts5Engine) MatchSQL(groups [][]string) (string, []interface{}) {
+	return "SELECT rowid FROM link_search_fts WHERE link_search_fts MATCH ?", []interface{}{fts5Query(groups)}
+}
+
+// fts5Query 每个查询词加引号按前缀匹配，组内任一命中、所有组都命中：("grafana"* OR "grafna"*) AND "监控"*
+func fts5Query(groups [][]string) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		alternatives := make([]string, len(group))
+		for j, term := range group {
+			alternatives[j] = `"` + term + `"*`
+		}
+		parts[i] = "(" + strings.Join(alternatives, " OR ") + ")"
+	}
+	return strings.Join(parts, " AND ")
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kk-nav/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// cond 编译后的 SQL 条件，nil 表示不限制
+type cond struct {
+	sql  string
+	vars []interface{}
+}
+
+// matchNone 不命中任何链接
+var matchNone = &cond{sql: "1 = 0"}
+
+// compiler 把语法树编译为针对 links 表的 SQL 条件。
+// 全文词通过搜索引擎转换为链接 ID 列表（最多 MaxHits 个），用于排序和高亮；
+// 被排除（NOT / -）的全文词编译为不限数量的子查询，保证命中的链接都被排除。
+type compiler struct {
+	db     *gorm.DB
+	userID uint
+
+	terms  []string // 高亮词
+	ranked [][]uint // 各全文词命中的链接 ID，按相关度排序
+}
+
+func (c *compiler) compile(node Node, negated bool) (*cond, error) {
+	switch n := node.(type) {
+	case *andNode:
+		return c.compileAnd(n, negated)
+	case *orNode:
+		var items []*cond
+		for _, item := range n.items {
+			ic, err := c.compile(item, negated)
+			if err != nil {
+				return nil, err
+			}
+			if ic == nil {
+				// 任一分支不限制时整个 OR 不限制
+				return nil, nil
+			}
+			items = append(items, ic)
+		}
+		return join(items, " OR "), nil
+	case *notNode:
+		ic, err := c.compile(n.item, !negated)
+		if err != nil || ic == nil {
+			return nil, err
+		}
+		return &cond{sql: "NOT (" + ic.sql + ")", vars: ic.vars}, nil
+	case *textNode:
+		if n.phrase {
+			return c.compilePhrase(n.text, negated)
+		}
+		return c.match(Terms(n.text), negated)
+	case *fieldNode:
+		return c.compileField(n)
+	default:
+		return nil, fmt.Errorf("unexpected search node %T", node)
+	}
+}
+
+// compileAnd 相邻的普通词合并为一次全文匹配，保持多词搜索的相关度排序
+func (c *compiler) compileAnd(n *andNode, negated bool) (*cond, error) {
+	var words []string
+	var items []*cond
+	for _, item := range n.items {
+		if text, ok := item.(*textNode); ok && !text.phrase {
+			words = append(words, text.text)
+			continue
+		}
+		ic, err := c.compile(item, negated)
+		if err != nil {
+			return nil, err
+		}
+		if ic != nil {
+			items = append(items, ic)
+		}
+	}
+	if len(words) > 0 {
+		ic, err := c.match(Terms(strings.Join(words, " ")), negated)
+		if err != nil {
+			return nil, err
+		}
+		if ic != nil {
+			items = append([]*cond{ic}, items...)
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return join(items, " AND "), nil
+}
+
+// match 全文匹配查询词（均需命中），没有可检索的字符时不限制
+func (c *compiler) match(terms []string, negated bool) (*cond, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	groups := vocab.expand(terms)
+	if negated {
+		return matchCond(groups), nil
+	}
+	ids, err := engine().Match(c.db, groups, MaxHits)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		c.terms = append(c.terms, group...)
+	}
+	c.ranked = append(c.ranked, ids)
+	return idsCond(ids), nil
+}
+
+// compilePhrase 短语先按全文索引粗筛，再要求标题、描述或 URL 中连续出现
+func (c *compiler) compilePhrase(phrase string, negated bool) (*cond, error) {
+	pattern := "%" + escapeLike(strings.ToLower(strings.TrimSpace(phrase))) + "%"
+	like := &cond{
+		sql:  `LOWER(links.title) LIKE ? ESCAPE '\' OR LOWER(links.description) LIKE ? ESCAPE '\' OR LOWER(links.url) LIKE ? ESCAPE '\'`,
+		vars: []interface{}{pattern, pattern, pattern},
+	}
+
+	terms := Terms(phrase)
+	if len(terms) == 0 {
+		return like, nil
+	}
+	// 短语按原文匹配，不做拼写纠错
+	if negated {
+		return join([]*cond{matchCond(toGroups(terms)), like}, " AND "), nil
+	}
+	ids, err := engine().Match(c.db, toGroups(terms), MaxHits)
+	if err != nil {
+		return nil, err
+	}
+	c.terms = append(c.terms, terms...)
+	c.ranked = append(c.ranked, ids)
+	return join([]*cond{idsCond(ids), like}, " AND "), nil
+}
+
+// compileField 限定词
+func (c *compiler) compileField(n *fieldNode) (*cond, error) {
+	value := strings.ToLower(n.value)
+	switch n.name {
+	case fieldTag:
+		return &cond{
+			sql:  "links.id IN (SELECT link_tags.link_id FROM link_tags JOIN tags ON tags.id = link_tags.tag_id WHERE LOWER(tags.name) = ?)",
+			vars: []interface{}{value},
+		}, nil
+	case fieldCat:
+		// 分类名或分类 ID
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+			return &cond{
+				sql:  "links.category_id IN (SELECT id FROM categories WHERE LOWER(name) = ? OR id = ?)",
+				vars: []interface{}{value, id},
+			}, nil
+		}
+		return &cond{
+			sql:  "links.category_id IN (SELECT id FROM categories WHERE LOWER(name) = ?)",
+			vars: []interface{}{value},
+		}, nil
+	case fieldStatus:
+		switch value {
+		case models.LinkStatusActive, models.LinkStatusDegraded, models.LinkStatusError, models.LinkStatusInactive:
+			return &cond{sql: "links.status = ?", vars: []interface{}{value}}, nil
+		}
+		return nil, &QueryError{Pos: n.pos, Msg: fmt.Sprintf("unknown status %q (expected active, degraded, error or inactive)", n.value)}
+	case fieldHost:
+		return c.compileHost(value)
+	case fieldIs:
+		if value != "favorite" {
+			return nil, &QueryError{Pos: n.pos, Msg: fmt.Sprintf("unknown is:%s (expected is:favorite)", n.value)}
+		}
+		if c.userID == 0 {
+			return nil, &QueryError{Pos: n.pos, Msg: "is:favorite requires login"}
+		}
+		return &cond{
+			sql:  "links.id IN (SELECT link_id FROM favorites WHERE user_id = ?)",
+			vars: []interface{}{c.userID},
+		}, nil
+	default:
+		return nil, &QueryError{Pos: n.pos, Msg: "unknown qualifier " + n.name}
+	}
+}
+
+// compileHost 按 URL 的主机名匹配，host:example.com 同时命中其子域名
+func (c *compiler) compileHost(host string) (*cond, error) {
+	host = strings.TrimPrefix(host, ".")
+	var rows []struct {
+		ID  uint
+		URL string
+	}
+	if err := c.db.Model(&models.Link{}).Select("id, url").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for _, row := range rows {
+		u, err := url.Parse(row.URL)
+		if err != nil {
+			continue
+		}
+		name := strings.ToLower(u.Hostname())
+		if name == host || strings.HasSuffix(name, "."+host) {
+			ids = append(ids, row.ID)
+		}
+	}
+	return idsCond(ids), nil
+}
+
+// rank 合并各全文词的排序：按在任一列表中的最好名次排序，名次相同时命中列表多的在前
+func (c *compiler) rank() []uint {
+	if len(c.ranked) == 1 {
+		return c.ranked[0]
+	}
+	best := map[uint]int{}
+	hits := map[uint]int{}
+	var ids []uint
+	for _, list := range c.ranked {
+		for i, id := range list {
+			if prev, ok := best[id]; !ok {
+				ids = append(ids, id)
+				best[id] = i
+			} else if i < prev {
+				best[id] = i
+			}
+			hits[id]++
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if best[a] != best[b] {
+			return best[a] < best[b]
+		}
+		return hits[a] > hits[b]
+	})
+	return ids
+}
+
+// idsCond 限定在指定链接内
+func idsCond(ids []uint) *cond {
+	if len(ids) == 0 {
+		return matchNone
+	}
+	return &cond{sql: "links.id IN ?", vars: []interface{}{ids}}
+}
+
+// matchCond 限定在全文命中的链接内（子查询，不限数量）
+func matchCond(groups [][]string) *cond {
+	sql, vars := engine().MatchSQL(groups)
+	return &cond{sql: "links.id IN (" + sql + ")", vars: vars}
+}
+
+// join 用 AND / OR 连接条件，每个条件加括号
+func join(items []*cond, op string) *cond {
+	if len(items) == 1 {
+		return items[0]
+	}
+	parts := make([]string, len(items))
+	var vars []interface{}
+	for i, item := range items {
+		parts[i] = "(" + item.sql + ")"
+		vars = append(vars, item.vars...)
+	}
+	return &cond{sql: strings.Join(parts, op), vars: vars}
+}
+
+// toGroups 每个查询词单独成组
+func toGroups(terms []string) [][]string {
+	groups := make([][]string, len(terms))
+	for i, term := range terms {
+		groups[i] = []string{term}
+	}
+	return groups
+}
+
+// escapeLike 转义 LIKE 通配符（配合 ESCAPE '\'）
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 拼写纠错参数
+const (
+	minFuzzyLength = 4 // 参与纠错的最短查询词
+	maxCorrections = 5 // 每个查询词最多补充的相近词
+)
+
+// vocabulary 索引中出现过的拉丁词（含拼音），用于查询词拼写纠错。
+// 只在启动重建和增量索引时增加，删除链接后残留的词只会多一次无结果的匹配。
+type vocabulary struct {
+	mu    sync.RWMutex
+	words map[string]struct{}
+}
+
+var vocab = &vocabulary{words: make(map[string]struct{})}
+
+// reset 清空词表
+func (v *vocabulary) reset() {
+	v.mu.Lock()
+	v.words = make(map[string]struct{})
+	v.mu.Unlock()
+}
+
+// add 加入索引文档中的拉丁词
+func (v *vocabulary) add(docs []Document) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, doc := range docs {
+		for _, field := range []string{doc.Title, doc.Tags, doc.Description, doc.URL} {
+			for _, word := range strings.Fields(field) {
+				if fuzzyCandidate(word) {
+					v.words[word] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+// expand 把查询词转换为查询词组：索引中没有以该词开头的词时，
+// 补充编辑距离相近的词（如 promethues -> prometheus），组内任一词命中即可
+func (v *vocabulary) expand(terms []string) [][]string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	groups := make([][]string, 0, len(terms))
+	for _, term := range terms {
+		group := []string{term}
+		if fuzzyCandidate(term) && !v.hasPrefix(term) {
+			group = append(group, v.similar(term)...)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// hasPrefix 词表中是否有以 prefix 开头的词
+func (v *vocabulary) hasPrefix(prefix string) bool {
+	for word := range v.words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// similar 编辑距离在允许范围内的词，按距离排序。
+// 同时和词的等长前缀比较，以便纠正输入到一半的词。
+func (v *vocabulary) similar(term string) []string {
+	t := []rune(term)
+	limit := 1
+	if len(t) >= 6 {
+		limit = 2
+	}
+
+	type candidate struct {
+		word     string
+		distance int
+	}
+	var candidates []candidate
+	for word := range v.words {
+		w := []rune(word)
+		if len(w) < len(t)-limit {
+			continue
+		}
+		d := limit + 1
+		if len(w) <= len(t)+limit {
+			d = editDistance(t, w)
+		}
+		if len(w) > len(t) {
+			d = min(d, editDistance(t, w[:len(t)]))
+		}
+		if d <= limit {
+			candidates = append(candidates, candidate{word, d})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].word < candidates[j].word
+	})
+	words := make([]string, 0, maxCorrections)
+	for i := 0; i < len(candidates) && i < maxCorrections; i++ {
+		words = append(words, candidates[i].word)
+	}
+	return words
+}
+
+// fuzzyCandidate 足够长、不是纯数字的拉丁词才参与纠错
+func fuzzyCandidate(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if !isWordRune(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters > 0 && len([]rune(word)) >= minFuzzyLength
+}
+
+// editDistance 编辑距离（相邻字符交换计为一次编辑）
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"kk-nav/internal/models"
+)
+
+// 高亮片段参数
+const (
+	snippetLength  = 120 // 描述片段的最大字数
+	snippetContext = 30  // 片段中第一个命中前保留的字数
+)
+
+// Highlight 为链接填充命中片段（标题、描述、URL、标签和分类），命中部分用 <mark> 包裹，其余内容已转义
+func (r *Result) Highlight(links []models.Link) {
+	if r == nil {
+		return
+	}
+	for i := range links {
+		fields := map[string]string{}
+		if s, ok := highlight(links[i].Title, r.Terms, 0, true); ok {
+			fields["title"] = s
+		}
+		if s, ok := highlight(links[i].Description, r.Terms, snippetLength, false); ok {
+			fields["description"] = s
+		}
+		if s, ok := highlight(links[i].URL, r.Terms, 0, false); ok {
+			fields["url"] = s
+		}
+		if len(links[i].Tags) > 0 {
+			names := make([]string, len(links[i].Tags))
+			for j, tag := range links[i].Tags {
+				names[j] = tag.Name
+			}
+			if s, ok := highlight(strings.Join(names, ", "), r.Terms, 0, true); ok {
+				fields["tags"] = s
+			}
+		}
+		if s, ok := highlight(links[i].Category.Name, r.Terms, 0, true); ok {
+			fields["category"] = s
+		}
+		if len(fields) > 0 {
+			links[i].Highlight = fields
+		}
+	}
+}
+
+// span 命中区间（按字计算）
+type span struct{ start, end int }
+
+// highlight 标记 text 中的命中词；maxLen > 0 时截取第一个命中附近的片段，
+// withPinyin 时同时标记拼音命中的汉字
+func highlight(text string, terms []string, maxLen int, withPinyin bool) (string, bool) {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var spans []span
+	for _, term := range terms {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			// 拉丁词只匹配词首，与索引的前缀匹配一致
+			if isWordRune(t[0]) && i > 0 && isWordRune(lower[i-1]) {
+				continue
+			}
+			if equalRunes(lower[i:i+len(t)], t) {
+				spans = append(spans, span{i, i + len(t)})
+			}
+		}
+	}
+	if withPinyin {
+		spans = append(spans, pinyinSpans(text, terms)...)
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+	spans = mergeSpans(spans)
+
+	from, to := 0, len(runes)
+	if maxLen > 0 && len(runes) > maxLen {
+		from = spans[0].start - snippetContext
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxLen
+		if to > len(runes) {
+			to = len(runes)
+			from = to - maxLen
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// pinyinSpans 查询词为汉字全拼前缀（jiankong -> 监控）或首字母（jk -> 监控）时对应的汉字区间
+func pinyinSpans(text string, terms []string) []span {
+	syllables := models.Syllables(text)
+	var spans []span
+	for _, term := range terms {
+		if len(term) < 2 || !isLetters(term) {
+			continue
+		}
+		for i := range syllables {
+			if syllables[i] == "" {
+				continue
+			}
+			// 全拼：从第 i 个字开始连续拼接，直到覆盖查询词
+			full, j := "", i
+			for j < len(syllables) && syllables[j] != "" && len(full) < len(term) {
+				full += syllables[j]
+				j++
+			}
+			if strings.HasPrefix(full, term) {
+				spans = append(spans, span{i, j})
+				continue
+			}
+			// 首字母
+			if i+len(term) <= len(syllables) {
+				matched := true
+				for k := 0; k < len(term); k++ {
+					if syllables[i+k] == "" || syllables[i+k][0] != term[k] {
+						matched = false
+						break
+					}
+				}
+				if matched {
+					spans = append(spans, span{i, i + len(term)})
+				}
+			}
+		}
+	}
+	return spans
+}
+
+// isLetters 只包含小写 ASCII 字母
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeSpans 排序并合并重叠或相邻的区间
+func mergeSpans(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
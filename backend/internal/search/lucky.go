@@ -0,0 +1,48 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"kk-nav/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// luckyMargin 名称匹配程度相同时，第一个链接的得分至少要领先第二个这么多才直接跳转
+const luckyMargin = 10.0
+
+// Lucky 为“手气不错”选出唯一最匹配的链接，没有足够明确的结果时返回 0：
+//   - 链接名称与输入完全一致，或名称（含单词、拼音、主机名）以输入开头且得分明显领先其他链接
+//   - 否则按全文搜索，只命中一个前台可见链接时采用该链接
+func Lucky(db *gorm.DB, q string, userID uint) (uint, error) {
+	var links []scoredSuggestion
+	for _, r := range rankSuggestions(db, q, userID) {
+		if r.Type == SuggestionLink && r.match > 0 {
+			links = append(links, r)
+		}
+	}
+	if len(links) > 0 {
+		top := links[0]
+		if top.match == matchExact {
+			return top.ID, nil
+		}
+		if top.match >= matchWord && (len(links) == 1 || top.Score-links[1].Score >= luckyMargin) {
+			return top.ID, nil
+		}
+	}
+
+	result, err := Query(db, q, userID)
+	if err != nil || result == nil {
+		return 0, err
+	}
+	var ids []uint
+	err = result.Filter(db.Model(&models.Link{}).Where("status IN ?", models.VisibleLinkStatuses)).
+		Limit(2).Pluck("links.id", &ids).Error
+	if err != nil || len(ids) != 1 {
+		return 0, err
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 搜索语法：
+//
+//	grafana 监控            所有词都要命中（全文搜索）
+//	"日志 分析"             短语，按原文连续匹配
+//	tag:k8s cat:监控        限定词：tag / cat / status / host / is:favorite，值可以加引号
+//	-tag:deprecated         排除
+//	grafana OR kibana       任一命中，OR 的优先级低于相邻词的 AND
+//	(a OR b) -c             括号分组
+//
+// 不认识的 name:value 按普通词处理（如 https://example.com）。
+
+// 限定词
+const (
+	fieldTag    = "tag"
+	fieldCat    = "cat"
+	fieldStatus = "status"
+	fieldHost   = "host"
+	fieldIs     = "is"
+)
+
+var fields = map[string]bool{
+	fieldTag:    true,
+	fieldCat:    true,
+	fieldStatus: true,
+	fieldHost:   true,
+	fieldIs:     true,
+}
+
+// QueryError 搜索语法错误（Pos 为从 1 开始的字符位置）
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	if e.Pos > 0 {
+		return fmt.Sprintf("invalid search query at position %d: %s", e.Pos, e.Msg)
+	}
+	return "invalid search query: " + e.Msg
+}
+
+// Node 语法树节点
+type Node interface {
+	String() string
+}
+
+type (
+	andNode  struct{ items []Node }
+	orNode   struct{ items []Node }
+	notNode  struct{ item Node }
+	textNode struct {
+		text   string
+		phrase bool
+	}
+	fieldNode struct {
+		name, value string
+		pos         int
+	}
+)
+
+func (n *andNode) String() string { return "(" + joinNodes(n.items, " ") + ")" }
+func (n *orNode) String() string  { return "(" + joinNodes(n.items, " OR ") + ")" }
+func (n *notNode) String() string { return "-" + n.item.String() }
+func (n *textNode) String() string {
+	if n.phrase {
+		return fmt.Sprintf("%q", n.text)
+	}
+	return n.text
+}
+func (n *fieldNode) String() string { return n.name + ":" + fmt.Sprintf("%q", n.value) }
+
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// token 词法单元
+type token struct {
+	kind  tokenKind
+	text  string // 词、短语内容或限定词的值
+	field string // 限定词名称
+	pos   int
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokField
+	tokNot
+	tokOr
+	tokLParen
+	tokRParen
+)
+
+// lex 切分搜索语句
+func lex(q string) ([]token, error) {
+	runes := []rune(q)
+	var tokens []token
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i + 1})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || !isTermRune(runes[i-1])):
+			tokens = append(tokens, token{kind: tokNot, pos: i + 1})
+			i++
+		case r == '"':
+			text, next, err := lexQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text, pos: i + 1})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && isTermRune(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if word == "OR" {
+				tokens = append(tokens, token{kind: tokOr, pos: start + 1})
+				continue
+			}
+
+			name, value, found := strings.Cut(word, ":")
+			name = strings.ToLower(name)
+			if !found || !fields[name] {
+				tokens = append(tokens, token{kind: tokWord, text: word, pos: start + 1})
+				continue
+			}
+			// 限定词的值可以加引号：cat:"开发 工具"
+			if value == "" && i < len(runes) && runes[i] == '"' {
+				text, next, err := lexQuoted(runes, i)
+				if err != nil {
+					return nil, err
+				}
+				value, i = text, next
+			}
+			if strings.TrimSpace(value) == "" {
+				return nil, &QueryError{Pos: start + 1, Msg: fmt.Sprintf("missing value for %s:", name)}
+			}
+			tokens = append(tokens, token{kind: tokField, field: name, text: value, pos: start + 1})
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes) + 1}), nil
+}
+
+// isTermRune 词中可以出现的字符（空白、引号和括号之外）
+func isTermRune(r rune) bool {
+	return !unicode.IsSpace(r) && r != '"' && r != '(' && r != ')'
+}
+
+// lexQuoted 读取从 runes[start] 的引号开始的短语，支持 \" 转义
+func lexQuoted(runes []rune, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				b.WriteRune(runes[i])
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &QueryError{Pos: start + 1, Msg: "unterminated quote"}
+}
+
+// parser 递归下降解析：or := and ("OR" and)*；and := unary+；unary := "-" unary | primary
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse 解析搜索语句，空语句返回 nil
+func Parse(q string) (Node, error) {
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &QueryError{Pos: t.pos, Msg: "unexpected \")\""}
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	if t := p.peek(); t.kind == tokOr {
+		return nil, &QueryError{Pos: t.pos, Msg: "OR must be preceded by a search term"}
+	}
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	items := []Node{first}
+	for p.peek().kind == tokOr {
+		or := p.next()
+		if k := p.peek().kind; k == tokEOF || k == tokOr || k == tokRParen {
+			return nil, &QueryError{Pos: or.pos, Msg: "OR must be followed by a search term"}
+		}
+		item, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if len(items) == 1 {
+		return first, nil
+	}
+	return &orNode{items: items}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var items []Node
+	for {
+		switch t := p.peek(); t.kind {
+		case tokEOF, tokOr, tokRParen:
+			if len(items) == 0 {
+				return nil, &QueryError{Pos: t.pos, Msg: "expected a search term"}
+			}
+			if len(items) == 1 {
+				return items[0], nil
+			}
+			return &andNode{items: items}, nil
+		}
+		item, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		not := p.next()
+		switch p.peek().kind {
+		case tokEOF, tokOr, tokRParen, tokNot:
+			return nil, &QueryError{Pos: not.pos, Msg: "\"-\" must be followed by a search term"}
+		}
+		item, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{item: item}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		if p.peek().kind == tokRParen {
+			return nil, &QueryError{Pos: t.pos, Msg: "empty parentheses"}
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, &QueryError{Pos: t.pos, Msg: "missing \")\""}
+		}
+		return node, nil
+	case tokWord:
+		return &textNode{text: t.text}, nil
+	case tokPhrase:
+		if strings.TrimSpace(t.text) == "" {
+			return nil, &QueryError{Pos: t.pos, Msg: "empty phrase"}
+		}
+		return &textNode{text: t.text, phrase: true}, nil
+	case tokField:
+		return &fieldNode{name: t.field, value: strings.TrimSpace(t.text), pos: t.pos}, nil
+	default:
+		return nil, &QueryError{Pos: t.pos, Msg: "expected a search term"}
+	}
+}
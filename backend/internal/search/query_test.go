@@ -0,0 +1,88 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // 语法树的 String()，空语句为 ""
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"grafana", "grafana"},
+		{"grafana 监控", "(grafana 监控)"},
+		{`"日志 分析"`, `"日志 分析"`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{"tag:k8s", `tag:"k8s"`},
+		{"TAG:k8s", `tag:"k8s"`},
+		{`cat:"开发 工具"`, `cat:"开发 工具"`},
+		{"is:favorite status:error", `(is:"favorite" status:"error")`},
+		{"-tag:deprecated", `-tag:"deprecated"`},
+		{"--a", "--a"},
+		{"- a", "(- a)"},
+		{"foo-bar", "foo-bar"},
+		{"https://example.com", "https://example.com"},
+		{"a OR b", "(a OR b)"},
+		{"a or b", "(a or b)"},
+		{"a OR b c", "(a OR (b c))"},
+		{"a b OR c", "((a b) OR c)"},
+		{"(a OR b) -c", "((a OR b) -c)"},
+		{"-(a OR b)", "-(a OR b)"},
+		{"((a))", "a"},
+	}
+	for _, tt := range tests {
+		node, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.query, err)
+			continue
+		}
+		got := ""
+		if node != nil {
+			got = node.String()
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{`"abc`, 1},
+		{`a "abc`, 3},
+		{`""`, 1},
+		{"tag:", 1},
+		{`cat:""`, 1},
+		{"OR a", 1},
+		{"a OR", 3},
+		{"a OR OR b", 3},
+		{"a OR )", 3},
+		{"()", 1},
+		{"(a", 1},
+		{"a)", 2},
+		{"-)", 1},
+		{`-"`, 2},
+	}
+	for _, tt := range tests {
+		node, err := Parse(tt.query)
+		var qe *QueryError
+		if !errors.As(err, &qe) {
+			t.Errorf("Parse(%q) = %v, %v; want QueryError", tt.query, node, err)
+			continue
+		}
+		if qe.Pos != tt.pos {
+			t.Errorf("Parse(%q) error at position %d, want %d (%v)", tt.query, qe.Pos, tt.pos, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package search 链接全文搜索：Postgres 使用 tsvector + GIN 索引，SQLite 使用 FTS5，
+// 两者通过同一个 Engine 接口提供按相关度排序的结果（标题 > 标签/分类 > 描述 > URL）。
+package search
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxHits 单次搜索返回的最大命中数
+const MaxHits = 1000
+
+// Engine 全文搜索引擎
+type Engine interface {
+	// Name 引擎名称
+	Name() string
+	// Migrate 创建索引表
+	Migrate(db *gorm.DB) error
+	// Save 写入（或覆盖）链接的索引文档
+	Save(db *gorm.DB, docs []Document) error
+	// Delete 删除链接的索引文档
+	Delete(db *gorm.DB, linkIDs []uint) error
+	// Clear 清空索引
+	Clear(db *gorm.DB) error
+	// Match 返回命中所有查询词组的链接 ID（组内任一词按前缀命中即可），按相关度从高到低排序
+	Match(db *gorm.DB, groups [][]string, limit int) ([]uint, error)
+	// MatchSQL 与 Match 条件相同、不限数量也不排序的子查询（SELECT 链接 ID），用于排除命中的链接
+	MatchSQL(groups [][]string) (string, []interface{})
+}
+
+var (
+	mu      sync.RWMutex
+	current Engine = likeEngine{}
+	logger         = zap.NewNop()
+)
+
+// Init 按数据库类型选择搜索引擎、创建索引表并重建索引，启动时在迁移之后调用一次
+func Init(db *gorm.DB, log *zap.Logger) error {
+	var e Engine
+	switch db.Dialector.Name() {
+	case "postgres":
+		e = postgresEngine{}
+	case "sqlite":
+		e = fts5Engine{}
+	default:
+		log.Warn("No full-text search index for this database, falling back to LIKE search",
+			zap.String("dialect", db.Dialector.Name()))
+		e = likeEngine{}
+	}
+	err := e.Migrate(db)
+	if err != nil && e.Name() == "fts5" && strings.Contains(err.Error(), "no such module") {
+		// 未启用 FTS5 的 SQLite 构建（需要 -tags sqlite_fts5）退化为 LIKE 匹配
+		log.Warn("SQLite FTS5 is not available, falling back to LIKE search (build with -tags sqlite_fts5)")
+		e = likeEngine{}
+		err = e.Migrate(db)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate %s search index: %w", e.Name(), err)
+	}
+
+	mu.Lock()
+	current = e
+	logger = log
+	mu.Unlock()
+
+	count, err := Rebuild(db)
+	if err != nil {
+		return fmt.Errorf("rebuild search index: %w", err)
+	}
+	log.Info("Search index ready", zap.String("engine", e.Name()), zap.Int("documents", count))
+	return nil
+}
+
+// engine 当前使用的搜索引擎
+func engine() Engine {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// EngineName 当前使用的搜索引擎名称
+func EngineName() string {
+	return engine().Name()
+}
+
+// Rebuild 清空并重建所有链接的索引，返回索引的链接数
+func Rebuild(db *gorm.DB) (int, error) {
+	e := engine()
+	count := 0
+	vocab.reset()
+	suggestions.invalidate()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := e.Clear(tx); err != nil {
+			return err
+		}
+		var links []models.Link
+		return tx.Preload("Category").Preload("Tags").Order("id").
+			FindInBatches(&links, 500, func(_ *gorm.DB, _ int) error {
+				count += len(links)
+				docs := documents(links)
+				vocab.add(docs)
+				return e.Save(tx, docs)
+			}).Error
+	})
+	return count, err
+}
+
+// Reindex 重建指定链接的索引（链接不存在时删除其索引），同时使补全索引失效。
+// 索引失败只记录日志，不影响链接本身的保存。
+func Reindex(db *gorm.DB, linkIDs ...uint) {
+	if len(linkIDs) == 0 {
+		return
+	}
+	e := engine()
+	suggestions.invalidate()
+
+	var links []models.Link
+	err := db.Preload("Category").Preload("Tags").Where("id IN ?", linkIDs).Find(&links).Error
+	if err == nil {
+		found := make(map[uint]bool, len(links))
+		for _, link := range links {
+			found[link.ID] = true
+		}
+		var missing []uint
+		for _, id := range linkIDs {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			err = e.Delete(db, missing)
+		}
+		if err == nil && len(links) > 0 {
+			docs := documents(links)
+			vocab.add(docs)
+			err = e.Save(db, docs)
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to update search index", zap.Uints("link_ids", linkIDs), zap.Error(err))
+	}
+}
+
+// ReindexCategory 分类改名后重建其下链接的索引
+func ReindexCategory(db *gorm.DB, categoryID uint) {
+	suggestions.invalidate()
+	var ids []uint
+	db.Model(&models.Link{}).Where("category_id = ?", categoryID).Pluck("id", &ids)
+	Reindex(db, ids...)
+}
+
+// ReindexTag 标签改名后重建使用该标签的链接的索引
+func ReindexTag(db *gorm.DB, tagID uint) {
+	suggestions.invalidate()
+	var ids []uint
+	db.Table("link_tags").Where("tag_id = ?", tagID).Pluck("link_id", &ids)
+	Reindex(db, ids...)
+}
+
+// Result 搜索结果
+type Result struct {
+	Query string
+	Terms []string // 查询词及纠错补充的相近词（用于高亮）
+	IDs   []uint   // 全文词命中的链接 ID，按相关度排序
+	cond  *cond    // 整条搜索语句编译后的过滤条件，nil 时不命中任何链接
+}
+
+// Query 解析并执行搜索语句（语法见 query.go），userID 用于 is:favorite，未登录时为 0。
+// 语句为空或没有任何限制条件时返回 nil，语法错误返回 *QueryError。
+func Query(db *gorm.DB, q string, userID uint) (*Result, error) {
+	node, err := Parse(q)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	c := &compiler{db: db, userID: userID}
+	where, err := c.compile(node, false)
+	if err != nil || where == nil {
+		return nil, err
+	}
+	return &Result{Query: q, Terms: dedupe(c.terms), IDs: c.rank(), cond: where}, nil
+}
+
+// Filter 把查询限制在命中的链接内
+func (r *Result) Filter(query *gorm.DB) *gorm.DB {
+	if r.cond == nil {
+		return query.Where("1 = 0")
+	}
+	return query.Where(clause.Expr{SQL: "(" + r.cond.sql + ")", Vars: r.cond.vars})
+}
+
+// Order 按相关度排序，没有全文词命中的链接排在后面
+func (r *Result) Order(query *gorm.DB) *gorm.DB {
+	if len(r.IDs) == 0 {
+		return query
+	}
+	var sql strings.Builder
+	vars := make([]interface{}, 0, len(r.IDs)*2+1)
+	sql.WriteString("CASE links.id")
+	for i, id := range r.IDs {
+		sql.WriteString(" WHEN ? THEN ?")
+		vars = append(vars, id, i)
+	}
+	sql.WriteString(" ELSE ? END")
+	vars = append(vars, len(r.IDs))
+	return query.Order(clause.OrderBy{Expression: clause.Expr{SQL: sql.String(), Vars: vars}})
+}
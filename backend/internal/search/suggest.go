@@ -0,0 +1,358 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 自动补全参数
+const (
+	DefaultSuggestions = 8  // 默认返回的补全数
+	MaxSuggestions     = 20 // 最多返回的补全数
+
+	suggestTTL    = time.Minute      // 补全索引的最长缓存时间，点击数和检测状态的变化在此之后生效
+	historyTTL    = 10 * time.Minute // 用户点击记录的缓存时间
+	historyWindow = 90 * 24 * time.Hour
+	maxHistories  = 1000 // 最多缓存的用户点击记录数
+)
+
+// 补全类型
+const (
+	SuggestionLink     = "link"
+	SuggestionCategory = "category"
+	SuggestionTag      = "tag"
+)
+
+// 匹配程度得分，相同匹配程度下按热度和用户自己的点击次数排序
+const (
+	matchExact     = 100.0 // 名称完全一致
+	matchPrefix    = 80.0  // 名称以输入开头
+	matchWord      = 60.0  // 名称中的单词、拼音或主机名以输入开头
+	matchSubstring = 40.0  // 名称包含输入
+	weightClicks   = 5.0   // 总点击数（取对数）的权重
+	weightHistory  = 15.0  // 用户自己点击次数（取对数）的权重
+)
+
+// Suggestion 补全候选
+type Suggestion struct {
+	Type     string  `json:"type"` // link | category | tag
+	ID       uint    `json:"id"`
+	Title    string  `json:"title"`
+	URL      string  `json:"url,omitempty"`
+	Icon     string  `json:"icon,omitempty"`
+	Color    string  `json:"color,omitempty"`
+	Category string  `json:"category,omitempty"`
+	Score    float64 `json:"score"`
+}
+
+// suggestEntry 补全索引条目
+type suggestEntry struct {
+	Suggestion
+	name   string   // 小写名称
+	keys   []string // 单词、拼音和主机名，均为小写
+	clicks int      // 链接总点击数，分类和标签为其下链接之和
+}
+
+// suggestIndex 链接、分类和标签名称的内存索引。链接、分类或标签修改后标记失效，
+// 在下一次补全时从数据库重建，此外每 suggestTTL 重建一次。
+type suggestIndex struct {
+	mu      sync.RWMutex
+	entries []*suggestEntry
+	links   map[uint]*suggestEntry
+	built   time.Time
+	stale   bool
+}
+
+// clickHistory 用户最近的链接点击次数，按需加载并缓存
+type clickHistory struct {
+	mu    sync.Mutex
+	users map[uint]*userClicks
+}
+
+type userClicks struct {
+	counts map[uint]int
+	loaded time.Time
+}
+
+var (
+	suggestions = &suggestIndex{stale: true}
+	history     = &clickHistory{users: make(map[uint]*userClicks)}
+)
+
+// invalidate 标记补全索引失效
+func (s *suggestIndex) invalidate() {
+	s.mu.Lock()
+	s.stale = true
+	s.mu.Unlock()
+}
+
+// ensure 索引失效或过期时重建
+func (s *suggestIndex) ensure(db *gorm.DB) {
+	s.mu.RLock()
+	fresh := !s.stale && time.Since(s.built) < suggestTTL
+	s.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stale && time.Since(s.built) < suggestTTL {
+		return
+	}
+	entries, links, err := loadSuggestEntries(db)
+	if err != nil {
+		// 保留旧索引，下次再试
+		logger.Error("Failed to build suggestion index", zap.Error(err))
+		return
+	}
+	s.entries, s.links = entries, links
+	s.built, s.stale = time.Now(), false
+}
+
+// loadSuggestEntries 读取前台可见的链接及其所属的启用分类和标签
+func loadSuggestEntries(db *gorm.DB) ([]*suggestEntry, map[uint]*suggestEntry, error) {
+	var links []models.Link
+	err := db.Select("id, title, title_pinyin, url, category_id, click_count").
+		Where("status IN ?", models.VisibleLinkStatuses).
+		Preload("Category").Preload("Tags").
+		Find(&links).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]*suggestEntry, 0, len(links))
+	byLink := make(map[uint]*suggestEntry, len(links))
+	categories := map[uint]*suggestEntry{}
+	tags := map[uint]*suggestEntry{}
+	for _, link := range links {
+		keys := append(suggestKeys(link.Title, link.TitlePinyin), hostKey(link.URL))
+		e := &suggestEntry{
+			Suggestion: Suggestion{
+				Type:     SuggestionLink,
+				ID:       link.ID,
+				Title:    link.Title,
+				URL:      link.URL,
+				Icon:     link.Category.Icon,
+				Category: link.Category.Name,
+			},
+			name:   strings.ToLower(link.Title),
+			keys:   keys,
+			clicks: link.ClickCount,
+		}
+		entries = append(entries, e)
+		byLink[link.ID] = e
+
+		if link.Category.Active {
+			ce, ok := categories[link.CategoryID]
+			if !ok {
+				ce = &suggestEntry{
+					Suggestion: Suggestion{
+						Type:  SuggestionCategory,
+						ID:    link.Category.ID,
+						Title: link.Category.Name,
+						Icon:  link.Category.Icon,
+						Color: link.Category.Color,
+					},
+					name: strings.ToLower(link.Category.Name),
+					keys: suggestKeys(link.Category.Name, link.Category.NamePinyin),
+				}
+				categories[link.CategoryID] = ce
+				entries = append(entries, ce)
+			}
+			ce.clicks += link.ClickCount
+		}
+
+		for _, tag := range link.Tags {
+			te, ok := tags[tag.ID]
+			if !ok {
+				te = &suggestEntry{
+					Suggestion: Suggestion{
+						Type:  SuggestionTag,
+						ID:    tag.ID,
+						Title: tag.Name,
+						Color: tag.Color,
+					},
+					name: strings.ToLower(tag.Name),
+					keys: suggestKeys(tag.Name, tag.NamePinyin),
+				}
+				tags[tag.ID] = te
+				entries = append(entries, te)
+			}
+			te.clicks += link.ClickCount
+		}
+	}
+	return entries, byLink, nil
+}
+
+// suggestKeys 名称中的单词和拼音检索词
+func suggestKeys(name, pinyin string) []string {
+	words, _ := segments(name)
+	return append(words, strings.Fields(pinyin)...)
+}
+
+// hostKey 去掉 www. 的主机名
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// matchScore 名称与输入的匹配程度，不匹配时返回 0
+func (e *suggestEntry) matchScore(prefix string) float64 {
+	switch {
+	case e.name == prefix:
+		return matchExact
+	case strings.HasPrefix(e.name, prefix):
+		return matchPrefix
+	}
+	for _, key := range e.keys {
+		if key != "" && strings.HasPrefix(key, prefix) {
+			return matchWord
+		}
+	}
+	if strings.Contains(e.name, prefix) {
+		return matchSubstring
+	}
+	return 0
+}
+
+// RecordClick 记录一次链接点击，立即计入补全排序
+func RecordClick(userID, linkID uint) {
+	suggestions.mu.Lock()
+	if e, ok := suggestions.links[linkID]; ok {
+		e.clicks++
+	}
+	suggestions.mu.Unlock()
+
+	if userID == 0 {
+		return
+	}
+	history.mu.Lock()
+	if h, ok := history.users[userID]; ok {
+		h.counts[linkID]++
+	}
+	history.mu.Unlock()
+}
+
+// clicks 用户最近 historyWindow 内各链接的点击次数
+func (h *clickHistory) clicks(db *gorm.DB, userID uint) map[uint]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if uc, ok := h.users[userID]; ok && time.Since(uc.loaded) < historyTTL {
+		return uc.counts
+	}
+
+	var rows []struct {
+		LinkID uint
+		Count  int
+	}
+	err := db.Model(&models.ClickLog{}).
+		Select("link_id, COUNT(*) AS count").
+		Where("user_id = ? AND created_at >= ?", userID, time.Now().Add(-historyWindow)).
+		Group("link_id").
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error("Failed to load click history", zap.Uint("user_id", userID), zap.Error(err))
+		return nil
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.LinkID] = row.Count
+	}
+
+	if len(h.users) >= maxHistories {
+		for id, uc := range h.users {
+			if time.Since(uc.loaded) >= historyTTL {
+				delete(h.users, id)
+			}
+		}
+		if len(h.users) >= maxHistories {
+			h.users = make(map[uint]*userClicks)
+		}
+	}
+	h.users[userID] = &userClicks{counts: counts, loaded: time.Now()}
+	return counts
+}
+
+// scoredSuggestion 带匹配程度的补全候选
+type scoredSuggestion struct {
+	Suggestion
+	match float64
+}
+
+// Suggest 返回与输入前缀最匹配的链接、分类和标签，按匹配程度、总点击数和用户自己的点击次数排序。
+// 输入为空时按热度返回常用链接，用于快速启动。userID 为 0 时不考虑个人点击记录。
+func Suggest(db *gorm.DB, prefix string, userID uint, limit int) []Suggestion {
+	if limit <= 0 {
+		limit = DefaultSuggestions
+	}
+	limit = min(limit, MaxSuggestions)
+
+	ranked := rankSuggestions(db, prefix, userID)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	results := make([]Suggestion, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.Suggestion
+	}
+	return results
+}
+
+// rankSuggestions 所有匹配的补全候选，按得分从高到低排序
+func rankSuggestions(db *gorm.DB, prefix string, userID uint) []scoredSuggestion {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+
+	var own map[uint]int
+	if userID != 0 {
+		own = history.clicks(db, userID)
+	}
+
+	suggestions.ensure(db)
+	suggestions.mu.RLock()
+	defer suggestions.mu.RUnlock()
+
+	var results []scoredSuggestion
+	for _, e := range suggestions.entries {
+		var match float64
+		if prefix == "" {
+			if e.Type != SuggestionLink {
+				continue
+			}
+		} else if match = e.matchScore(prefix); match == 0 {
+			continue
+		}
+		score := match + weightClicks*math.Log1p(float64(e.clicks))
+		if e.Type == SuggestionLink {
+			score += weightHistory * math.Log1p(float64(own[e.ID]))
+		}
+		r := scoredSuggestion{Suggestion: e.Suggestion, match: match}
+		r.Score = math.Round(score*100) / 100
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Title < results[j].Title
+	})
+	return results
+}
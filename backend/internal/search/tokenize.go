@@ -0,0 +1,120 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isCJK 判断是否为中日韩文字（按字切分，不依赖数据库分词器）
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// isWordRune 拉丁字母和数字
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// segments 把文本切分为单词和连续的中日韩文字片段（均已转小写）
+func segments(text string) (words []string, cjk [][]rune) {
+	var word []rune
+	var run []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+		if len(run) > 0 {
+			cjk = append(cjk, append([]rune{}, run...))
+			run = run[:0]
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			if len(word) > 0 {
+				words = append(words, strings.ToLower(string(word)))
+				word = word[:0]
+			}
+			run = append(run, r)
+		case isWordRune(r):
+			if len(run) > 0 {
+				cjk = append(cjk, append([]rune{}, run...))
+				run = run[:0]
+			}
+			word = append(word, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words, cjk
+}
+
+// Tokenize 把文本切分为索引词：拉丁字母和数字按单词切分，中日韩文字切分为单字和相邻双字，
+// 这样查询任意一个或两个字都能命中，长词按双字组合匹配。
+func Tokenize(text string) []string {
+	words, cjk := segments(text)
+	tokens := words
+	for _, run := range cjk {
+		for i := range run {
+			tokens = append(tokens, string(run[i]))
+			if i+1 < len(run) {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+		}
+	}
+	return tokens
+}
+
+// Terms 把搜索词切分为查询词：单词原样保留，中日韩文字切分为相邻双字（只有一个字时为单字），
+// 所有查询词都需要命中（按前缀匹配）。
+func Terms(q string) []string {
+	words, cjk := segments(q)
+	terms := words
+	for _, run := range cjk {
+		if len(run) == 1 {
+			terms = append(terms, string(run))
+			continue
+		}
+		for i := 0; i+1 < len(run); i++ {
+			terms = append(terms, string(run[i:i+2]))
+		}
+	}
+	return dedupe(terms)
+}
+
+// dedupe 去重并保持顺序
+func dedupe(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	out := items[:0]
+	for _, item := range items {
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		out = append(out, item)
+	}
+	return out
+}
+
+// indexText 拼接索引词，前置空格便于按词首匹配（LIKE '% term%'）
+func indexText(texts ...string) string {
+	var b strings.Builder
+	for _, text := range texts {
+		for _, token := range Tokenize(text) {
+			b.WriteByte(' ')
+			b.WriteString(token)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kk-nav/internal/config"
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrCheckingDisabled 检测服务已关闭或暂停，不接受检测代理上报的结果
+var ErrCheckingDisabled = errors.New("link checking is disabled or paused")
+
+// AgentLink 下发给检测代理的链接及检测参数
+type AgentLink struct {
+	ID             uint             `json:"id"`
+	URL            string           `json:"url"`
+	Endpoints      []AgentEndpoint  `json:"endpoints,omitempty"` // 与链接 URL 不同的环境地址，使用链接的检测参数单独检测
+	Probe          models.LinkProbe `json:"probe"`
+	Retries        int              `json:"retries"`
+	RetryBackoffMs int64            `json:"retry_backoff_ms"`
+}
+
+// AgentEndpoint 下发给检测代理的环境地址
+type AgentEndpoint struct {
+	ID          uint   `json:"id"`
+	Environment string `json:"environment"`
+	URL         string `json:"url"`
+}
+
+// AgentResult 检测代理上报的单条检测结果
+type AgentResult struct {
+	LinkID      uint                 `json:"link_id" binding:"required"`
+	EndpointID  uint                 `json:"endpoint_id,omitempty"` // 不为 0 时是环境地址的检测结果
+	Status      string               `json:"status" binding:"required,oneof=active error"`
+	StatusCode  int                  `json:"status_code"`
+	LatencyMs   int64                `json:"latency_ms"`
+	ErrorClass  string               `json:"error_class"`
+	Error       string               `json:"error"`
+	Certificate *CertificateInfo     `json:"certificate,omitempty"`
+	Redirects   []models.RedirectHop `json:"redirects,omitempty"`
+	FinalURL    string               `json:"final_url,omitempty"`
+	CheckedAt   time.Time            `json:"checked_at"`
+}
+
+// NewAgentLinks 按当前检测策略生成下发给代理的链接
+func NewAgentLinks(links []models.Link) []AgentLink {
+	policy := loadPolicy()
+	items := make([]AgentLink, 0, len(links))
+	for i := range links {
+		link := &links[i]
+		p := policy.forLink(link)
+		item := AgentLink{
+			ID:             link.ID,
+			URL:            link.DefaultURL(),
+			Probe:          link.Probe,
+			Retries:        p.retries,
+			RetryBackoffMs: p.retryBackoff.Milliseconds(),
+		}
+		// 与链接 URL 相同的环境地址沿用链接的检测结果，不重复下发
+		for _, e := range link.Endpoints {
+			if e.URL != link.URL {
+				item.Endpoints = append(item.Endpoints, AgentEndpoint{
+					ID:          e.ID,
+					Environment: e.Environment,
+					URL:         link.DefaultURLFor(e.URL),
+				})
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// NewAgentResult 把探测结果转换为上报格式
+func NewAgentResult(linkID uint, result ProbeResult) AgentResult {
+	return AgentResult{
+		LinkID:      linkID,
+		Status:      result.Status,
+		StatusCode:  result.StatusCode,
+		LatencyMs:   result.Latency.Milliseconds(),
+		ErrorClass:  result.ErrorClass,
+		Error:       result.ErrorMessage(),
+		Certificate: result.Certificate,
+		Redirects:   result.Redirects,
+		FinalURL:    result.FinalURL,
+		CheckedAt:   result.CheckedAt,
+	}
+}
+
+// probeResult 还原为探测结果，修正明显无效的字段
+func (r AgentResult) probeResult(url string, now time.Time) ProbeResult {
+	result := ProbeResult{
+		URL:         url,
+		Status:      r.Status,
+		StatusCode:  r.StatusCode,
+		Latency:     time.Duration(r.LatencyMs) * time.Millisecond,
+		ErrorClass:  r.ErrorClass,
+		Certificate: r.Certificate,
+		Redirects:   r.Redirects,
+		FinalURL:    r.FinalURL,
+		CheckedAt:   r.CheckedAt,
+	}
+	if result.Status != models.LinkStatusActive {
+		result.Status = models.LinkStatusError
+		if !models.IsErrorClass(result.ErrorClass) {
+			result.ErrorClass = models.ErrorClassNetwork
+		}
+		if r.Error != "" {
+			result.Err = errors.New(r.Error)
+		}
+	} else {
+		result.ErrorClass = ""
+	}
+	if result.Latency < 0 {
+		result.Latency = 0
+	}
+	// 代理时钟不可信：缺失或超前的时间使用服务端时间
+	if result.CheckedAt.IsZero() || result.CheckedAt.After(now) {
+		result.CheckedAt = now
+	}
+	return result
+}
+
+// ProbeAgentLinks 检测代理使用：并发探测下发的链接及其环境地址，每个结果通过 fn 回调（可能被并发调用）
+func ProbeAgentLinks(ctx context.Context, prober *Prober, cfg config.CheckerConfig, links []AgentLink, fn func(AgentResult)) {
+	type owner struct {
+		linkID, endpointID uint
+	}
+	var owners []owner
+	var targets []probeTarget
+	for _, link := range links {
+		policy := checkPolicy{
+			retries:      link.Retries,
+			retryBackoff: time.Duration(link.RetryBackoffMs) * time.Millisecond,
+		}
+		owners = append(owners, owner{linkID: link.ID})
+		targets = append(targets, probeTarget{url: link.URL, probe: link.Probe, policy: policy})
+		for _, e := range link.Endpoints {
+			owners = append(owners, owner{linkID: link.ID, endpointID: e.ID})
+			targets = append(targets, probeTarget{url: e.URL, probe: link.Probe, policy: policy})
+		}
+	}
+	probePool(ctx, prober, cfg, targets, func(i int, result ProbeResult) {
+		r := NewAgentResult(owners[i].linkID, result)
+		r.EndpointID = owners[i].endpointID
+		fn(r)
+	})
+}
+
+// ApplyAgentResults 保存检测代理上报的结果，按阈值切换链接状态并发送通知；
+// 环境地址的结果只更新该地址的最近一次检测结果。
+// 只接受属于代理所在区域、且参与检测的链接，返回实际保存的结果数。
+func (lc *LinkChecker) ApplyAgentResults(agent *models.Agent, results []AgentResult) (int, error) {
+	if len(results) == 0 {
+		return 0, nil
+	}
+	if status := lc.Status(); !status.Enabled || status.Paused {
+		return 0, ErrCheckingDisabled
+	}
+
+	ids := make([]uint, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.LinkID)
+	}
+
+	var links []models.Link
+	if err := lc.db.Where("id IN ? AND zone = ? AND status IN ?", ids, agent.Zone, models.CheckedLinkStatuses).
+		Find(&links).Error; err != nil {
+		return 0, err
+	}
+	byID := make(map[uint]*models.Link, len(links))
+	linkIDs := make([]uint, len(links))
+	for i := range links {
+		byID[links[i].ID] = &links[i]
+		linkIDs[i] = links[i].ID
+	}
+
+	var endpointIDs []uint
+	for _, r := range results {
+		if r.EndpointID != 0 {
+			endpointIDs = append(endpointIDs, r.EndpointID)
+		}
+	}
+	endpoints := map[uint]models.LinkEndpoint{}
+	if len(endpointIDs) > 0 && len(linkIDs) > 0 {
+		var items []models.LinkEndpoint
+		if err := lc.db.Where("id IN ? AND link_id IN ?", endpointIDs, linkIDs).Find(&items).Error; err != nil {
+			return 0, err
+		}
+		for _, e := range items {
+			endpoints[e.ID] = e
+		}
+	}
+
+	now := time.Now()
+	policy := loadPolicy()
+	maintenance := lc.maintenance(now)
+	events := &eventBuffer{}
+	applied := 0
+	for _, r := range results {
+		link, ok := byID[r.LinkID]
+		if !ok {
+			continue
+		}
+		w := maintenance.For(link.ID)
+		if w != nil && w.Mode == models.MaintenanceSkip {
+			continue
+		}
+
+		if r.EndpointID != 0 {
+			// 环境地址与中心检测一样在维护期间不更新
+			e, ok := endpoints[r.EndpointID]
+			if !ok || e.LinkID != link.ID || w != nil {
+				continue
+			}
+			lc.saveEndpointResult(lc.db.Where("id = ?", e.ID), r.probeResult(link.DefaultURLFor(e.URL), now))
+			applied++
+			continue
+		}
+
+		result := r.probeResult(link.DefaultURL(), now)
+		lc.recordResult(link.ID, result, "agent")
+		from := link.Status
+		if w == nil {
+			lc.mirrorEndpoints(link, result)
+			if lc.saveResult(link, result, policy, false) {
+				events.add(link, from, result)
+			}
+		}
+		applied++
+	}
+
+	if ignored := len(results) - applied; ignored > 0 {
+		lc.logger.Warn("Ignored agent results for links outside its zone or in maintenance",
+			zap.String("agent", agent.Name),
+			zap.String("zone", agent.Zone),
+			zap.Int("ignored", ignored))
+	}
+
+	lc.notify(events.events)
+	return applied, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// checkEndpoints 检测链接各环境的地址，使用所属链接的探测配置。
+// 与链接 URL 相同的环境地址不重复探测，由 mirrorEndpoints 沿用链接的检测结果。
+// 环境地址只记录最近一次检测结果，不参与链接状态、阈值和通知。
+func (lc *LinkChecker) checkEndpoints(ctx context.Context, links []models.Link, policy checkPolicy) {
+	if len(links) == 0 {
+		return
+	}
+	byID := make(map[uint]*models.Link, len(links))
+	ids := make([]uint, len(links))
+	for i := range links {
+		byID[links[i].ID] = &links[i]
+		ids[i] = links[i].ID
+	}
+
+	var endpoints []models.LinkEndpoint
+	if err := lc.db.Where("link_id IN ?", ids).Find(&endpoints).Error; err != nil {
+		lc.logger.Error("Failed to fetch link endpoints for status check", zap.Error(err))
+		return
+	}
+
+	var pending []models.LinkEndpoint
+	var targets []probeTarget
+	for _, e := range endpoints {
+		link := byID[e.LinkID]
+		if e.URL == link.URL {
+			continue
+		}
+		pending = append(pending, e)
+		targets = append(targets, probeTarget{url: link.DefaultURLFor(e.URL), probe: link.Probe, policy: policy.forLink(link)})
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	probePool(ctx, lc.prober, lc.cfg, targets, func(i int, result ProbeResult) {
+		lc.saveEndpointResult(lc.db.Where("id = ?", pending[i].ID), result)
+	})
+}
+
+// mirrorEndpoints 把链接的检测结果写入 URL 与链接相同的环境地址
+func (lc *LinkChecker) mirrorEndpoints(link *models.Link, result ProbeResult) {
+	lc.saveEndpointResult(lc.db.Where("link_id = ? AND url = ?", link.ID, link.URL), result)
+}
+
+// saveEndpointResult 更新 query 选中的环境地址的最近一次检测结果
+func (lc *LinkChecker) saveEndpointResult(query *gorm.DB, result ProbeResult) {
+	now := result.CheckedAt
+	err := query.Model(&models.LinkEndpoint{}).Updates(map[string]interface{}{
+		"status":           result.Status,
+		"last_checked_at":  &now,
+		"last_status_code": result.StatusCode,
+		"last_latency_ms":  result.Latency.Milliseconds(),
+		"last_error_class": result.ErrorClass,
+		"last_error":       result.ErrorMessage(),
+	}).Error
+	if err != nil {
+		lc.logger.Error("Failed to update link endpoint status", zap.Error(err))
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// jobRetention 已结束的批量检测任务保留时长
+const jobRetention = time.Hour
+
+// 批量检测任务状态
+const (
+	JobStateRunning   = "running"
+	JobStateCompleted = "completed"
+	JobStateCancelled = "cancelled"
+)
+
+// checkJob 后台批量检测任务
+type checkJob struct {
+	id        string
+	createdAt time.Time
+	cancel    context.CancelFunc
+
+	mu         sync.Mutex
+	state      string
+	total      int
+	finishedAt *time.Time
+	results    []CheckJobResult
+}
+
+// CheckJobResult 批量检测中单个链接的结果
+type CheckJobResult struct {
+	LinkID     uint      `json:"link_id"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Status     string    `json:"status"`
+	StatusCode int       `json:"status_code"`
+	LatencyMs  int64     `json:"latency_ms"`
+	ErrorClass string    `json:"error_class,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// CheckJob 批量检测任务快照
+type CheckJob struct {
+	ID         string           `json:"id"`
+	State      string           `json:"state"`
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at"`
+	Total      int              `json:"total"`
+	Processed  int              `json:"processed"`
+	Active     int              `json:"active"`
+	Error      int              `json:"error"`
+	Percent    float64          `json:"percent"`
+	Results    []CheckJobResult `json:"results,omitempty"`
+}
+
+func (j *checkJob) snapshot(withResults bool) CheckJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	snap := CheckJob{
+		ID:         j.id,
+		State:      j.state,
+		CreatedAt:  j.createdAt,
+		FinishedAt: j.finishedAt,
+		Total:      j.total,
+		Processed:  len(j.results),
+	}
+	for _, result := range j.results {
+		if result.Status == models.LinkStatusActive {
+			snap.Active++
+		} else {
+			snap.Error++
+		}
+	}
+	if snap.Total > 0 {
+		snap.Percent = float64(snap.Processed) / float64(snap.Total) * 100
+	}
+	if withResults {
+		snap.Results = append([]CheckJobResult{}, j.results...)
+	}
+	return snap
+}
+
+// StartJob 在后台批量检测链接并保存结果，立即返回任务快照
+func (lc *LinkChecker) StartJob(links []models.Link) CheckJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &checkJob{
+		id:        newJobID(),
+		createdAt: time.Now(),
+		cancel:    cancel,
+		state:     JobStateRunning,
+		total:     len(links),
+		results:   make([]CheckJobResult, 0, len(links)),
+	}
+
+	lc.jobsMu.Lock()
+	lc.pruneJobs()
+	lc.jobs[job.id] = job
+	lc.jobsMu.Unlock()
+
+	go lc.runJob(ctx, job, links)
+
+	lc.logger.Info("Batch link check started",
+		zap.String("job_id", job.id),
+		zap.Int("count", len(links)))
+	return job.snapshot(false)
+}
+
+// Job 返回批量检测任务快照
+func (lc *LinkChecker) Job(id string) (CheckJob, bool) {
+	lc.jobsMu.Lock()
+	job, ok := lc.jobs[id]
+	lc.jobsMu.Unlock()
+	if !ok {
+		return CheckJob{}, false
+	}
+	return job.snapshot(true), true
+}
+
+// Jobs 返回所有保留中的批量检测任务（不含逐条结果），按创建时间倒序
+func (lc *LinkChecker) Jobs() []CheckJob {
+	lc.jobsMu.Lock()
+	lc.pruneJobs()
+	jobs := make([]CheckJob, 0, len(lc.jobs))
+	for _, job := range lc.jobs {
+		jobs = append(jobs, job.snapshot(false))
+	}
+	lc.jobsMu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// CancelJob 取消批量检测任务，已完成的结果保留
+func (lc *LinkChecker) CancelJob(id string) (CheckJob, bool) {
+	lc.jobsMu.Lock()
+	job, ok := lc.jobs[id]
+	lc.jobsMu.Unlock()
+	if !ok {
+		return CheckJob{}, false
+	}
+
+	job.cancel()
+	return job.snapshot(false), true
+}
+
+// runJob 执行批量检测任务
+func (lc *LinkChecker) runJob(ctx context.Context, job *checkJob, links []models.Link) {
+	// 服务停止时取消任务
+	go func() {
+		select {
+		case <-lc.stop:
+			job.cancel()
+		case <-ctx.Done():
+		}
+	}()
+	defer job.cancel()
+
+	lc.CheckLinks(ctx, links, func(link models.Link, result ProbeResult) {
+		job.mu.Lock()
+		job.results = append(job.results, CheckJobResult{
+			LinkID:     link.ID,
+			Title:      link.Title,
+			URL:        link.DefaultURL(),
+			Status:     result.Status,
+			StatusCode: result.StatusCode,
+			LatencyMs:  result.Latency.Milliseconds(),
+			ErrorClass: result.ErrorClass,
+			Error:      result.ErrorMessage(),
+			CheckedAt:  result.CheckedAt,
+		})
+		job.mu.Unlock()
+	})
+
+	now := time.Now()
+	job.mu.Lock()
+	job.finishedAt = &now
+	if ctx.Err() != nil && len(job.results) < job.total {
+		job.state = JobStateCancelled
+	} else {
+		job.state = JobStateCompleted
+	}
+	state, processed := job.state, len(job.results)
+	job.mu.Unlock()
+
+	lc.logger.Info("Batch link check finished",
+		zap.String("job_id", job.id),
+		zap.String("state", state),
+		zap.Int("total", job.total),
+		zap.Int("processed", processed),
+		zap.Duration("duration", now.Sub(job.createdAt)))
+}
+
+// pruneJobs 清理过期的已结束任务，调用方需持有 jobsMu
+func (lc *LinkChecker) pruneJobs() {
+	cutoff := time.Now().Add(-jobRetention)
+	for id, job := range lc.jobs {
+		job.mu.Lock()
+		expired := job.finishedAt != nil && job.finishedAt.Before(cutoff)
+		job.mu.Unlock()
+		if expired {
+			delete(lc.jobs, id)
+		}
+	}
+}
+
+// newJobID 生成任务ID
+func newJobID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(bytes)
+}
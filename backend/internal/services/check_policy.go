@@ -0,0 +1,85 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"time"
+
+	"kk-nav/internal/models"
+)
+
+// checkPolicy 一次检测使用的阈值和重试参数（来自系统设置，可被链接的探测配置覆盖）
+type checkPolicy struct {
+	degradedThreshold int
+	failureThreshold  int
+	successThreshold  int
+	retries           int
+	retryBackoff      time.Duration
+	certWarningDays   int
+}
+
+// loadPolicy 读取系统设置中的检测策略
+func loadPolicy() checkPolicy {
+	policy := checkPolicy{
+		degradedThreshold: models.GetSettingInt("check_degraded_threshold", 2),
+		failureThreshold:  models.GetSettingInt("check_failure_threshold", 2),
+		successThreshold:  models.GetSettingInt("check_success_threshold", 1),
+		retries:           models.GetSettingInt("check_retries", 1),
+		retryBackoff:      time.Duration(models.GetSettingInt("check_retry_backoff_ms", 1000)) * time.Millisecond,
+		certWarningDays:   models.GetSettingInt("cert_warning_days", 14),
+	}
+	if policy.degradedThreshold < 1 {
+		policy.degradedThreshold = 1
+	}
+	if policy.failureThreshold < 1 {
+		policy.failureThreshold = 1
+	}
+	if policy.successThreshold < 1 {
+		policy.successThreshold = 1
+	}
+	if policy.retries < 0 {
+		policy.retries = 0
+	}
+	return policy
+}
+
+// forLink 合并链接自身的阈值配置
+func (p checkPolicy) forLink(link *models.Link) checkPolicy {
+	if link.Probe.FailureThreshold > 0 {
+		p.failureThreshold = link.Probe.FailureThreshold
+	}
+	if link.Probe.SuccessThreshold > 0 {
+		p.successThreshold = link.Probe.SuccessThreshold
+	}
+	if link.Probe.Retries != nil {
+		p.retries = *link.Probe.Retries
+	}
+	return p
+}
+
+// nextStatus 根据连续成功/失败次数计算链接的新状态：
+// 连续失败达到 degraded 阈值时 active 降为 degraded（偶发的单次失败保持 active），达到失败阈值才标记为 error，
+// degraded 阈值不小于失败阈值时直接从 active 进入 error；
+// error 需要连续成功达到阈值才恢复为 active，degraded 成功一次即恢复。
+func (p checkPolicy) nextStatus(link *models.Link, up bool) string {
+	if up {
+		if link.Status == models.LinkStatusError && link.ConsecutiveSuccesses < p.successThreshold {
+			return models.LinkStatusError
+		}
+		return models.LinkStatusActive
+	}
+
+	if link.ConsecutiveFailures >= p.failureThreshold {
+		return models.LinkStatusError
+	}
+	if link.Status == models.LinkStatusError {
+		return models.LinkStatusError
+	}
+	if link.ConsecutiveFailures >= p.degradedThreshold {
+		return models.LinkStatusDegraded
+	}
+	return models.LinkStatusActive
+}
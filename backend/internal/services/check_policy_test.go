@@ -0,0 +1,72 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"testing"
+
+	"kk-nav/internal/models"
+)
+
+func TestNextStatus(t *testing.T) {
+	defaults := checkPolicy{degradedThreshold: 2, failureThreshold: 3, successThreshold: 2}
+
+	tests := []struct {
+		name      string
+		policy    checkPolicy
+		status    string
+		up        bool
+		failures  int // 本次检测后的连续失败次数
+		successes int // 本次检测后的连续成功次数
+		want      string
+	}{
+		{"active stays active on success", defaults, models.LinkStatusActive, true, 0, 1, models.LinkStatusActive},
+		{"single failure keeps active", defaults, models.LinkStatusActive, false, 1, 0, models.LinkStatusActive},
+		{"degraded threshold reached", defaults, models.LinkStatusActive, false, 2, 0, models.LinkStatusDegraded},
+		{"degraded stays below failure threshold", defaults, models.LinkStatusDegraded, false, 2, 0, models.LinkStatusDegraded},
+		{"failure threshold reached", defaults, models.LinkStatusDegraded, false, 3, 0, models.LinkStatusError},
+		{"error stays error on failure", defaults, models.LinkStatusError, false, 5, 0, models.LinkStatusError},
+		{"degraded recovers on one success", defaults, models.LinkStatusDegraded, true, 0, 1, models.LinkStatusActive},
+		{"error needs success threshold", defaults, models.LinkStatusError, true, 0, 1, models.LinkStatusError},
+		{"error recovers at success threshold", defaults, models.LinkStatusError, true, 0, 2, models.LinkStatusActive},
+		{"degraded below lowered threshold", defaults, models.LinkStatusDegraded, false, 1, 0, models.LinkStatusActive},
+		{
+			"degraded threshold not below failure threshold skips degraded",
+			checkPolicy{degradedThreshold: 2, failureThreshold: 2, successThreshold: 1},
+			models.LinkStatusActive, false, 2, 0, models.LinkStatusError,
+		},
+		{
+			"failure threshold of one",
+			checkPolicy{degradedThreshold: 1, failureThreshold: 1, successThreshold: 1},
+			models.LinkStatusActive, false, 1, 0, models.LinkStatusError,
+		},
+		{
+			"degraded threshold of one",
+			checkPolicy{degradedThreshold: 1, failureThreshold: 3, successThreshold: 1},
+			models.LinkStatusActive, false, 1, 0, models.LinkStatusDegraded,
+		},
+	}
+	for _, tt := range tests {
+		link := &models.Link{
+			Status:               tt.status,
+			ConsecutiveFailures:  tt.failures,
+			ConsecutiveSuccesses: tt.successes,
+		}
+		if got := tt.policy.nextStatus(link, tt.up); got != tt.want {
+			t.Errorf("%s: nextStatus(%s, up=%v) = %s, want %s", tt.name, tt.status, tt.up, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyForLink(t *testing.T) {
+	retries := 0
+	link := &models.Link{Probe: models.LinkProbe{FailureThreshold: 5, Retries: &retries}}
+
+	p := checkPolicy{degradedThreshold: 2, failureThreshold: 2, successThreshold: 3, retries: 1}.forLink(link)
+	if p.failureThreshold != 5 || p.successThreshold != 3 || p.retries != 0 || p.degradedThreshold != 2 {
+		t.Errorf("forLink = %+v", p)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// settingsPollInterval 定期重新读取设置，兼容直接修改数据库或多实例部署
+	settingsPollInterval = time.Minute
+	// minCheckInterval 检测间隔下限
+	minCheckInterval = 5 * time.Minute
+)
+
+// 检测触发来源
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// checkSchedule 调度设置
+type checkSchedule struct {
+	enabled  bool
+	interval time.Duration
+}
+
+// runProgress 当前检测进度
+type runProgress struct {
+	trigger   string
+	startedAt time.Time
+	total     int64
+	active    int64
+	errored   int64
+	changed   int64
+}
+
+// RunProgress 当前检测进度快照
+type RunProgress struct {
+	Trigger   string    `json:"trigger"`
+	StartedAt time.Time `json:"started_at"`
+	Total     int64     `json:"total"`
+	Processed int64     `json:"processed"`
+	Active    int64     `json:"active"`
+	Error     int64     `json:"error"`
+	Changed   int64     `json:"changed"`
+	Percent   float64   `json:"percent"`
+}
+
+// RunSummary 一次检测的汇总
+type RunSummary struct {
+	Trigger        string        `json:"trigger"`
+	StartedAt      time.Time     `json:"started_at"`
+	FinishedAt     time.Time     `json:"finished_at"`
+	Duration       time.Duration `json:"-"`
+	DurationMs     int64         `json:"duration_ms"`
+	Total          int64         `json:"total"`
+	Processed      int64         `json:"processed"`
+	Active         int64         `json:"active"`
+	Error          int64         `json:"error"`
+	Changed        int64         `json:"changed"`
+	LinksPerSecond float64       `json:"links_per_second"`
+	Cancelled      bool          `json:"cancelled"`
+}
+
+// CheckerStatus 检测服务状态
+type CheckerStatus struct {
+	Enabled       bool         `json:"enabled"`
+	Paused        bool         `json:"paused"`
+	Running       bool         `json:"running"`
+	IntervalHours float64      `json:"interval_hours"`
+	NextRunAt     *time.Time   `json:"next_run_at"`
+	Current       *RunProgress `json:"current"`
+	LastRun       *RunSummary  `json:"last_run"`
+}
+
+func (p *runProgress) snapshot() RunProgress {
+	active := atomic.LoadInt64(&p.active)
+	errored := atomic.LoadInt64(&p.errored)
+	snap := RunProgress{
+		Trigger:   p.trigger,
+		StartedAt: p.startedAt,
+		Total:     p.total,
+		Processed: active + errored,
+		Active:    active,
+		Error:     errored,
+		Changed:   atomic.LoadInt64(&p.changed),
+	}
+	if snap.Total > 0 {
+		snap.Percent = float64(snap.Processed) / float64(snap.Total) * 100
+	}
+	return snap
+}
+
+func (p *runProgress) summary(finishedAt time.Time, cancelled bool) RunSummary {
+	snap := p.snapshot()
+	duration := finishedAt.Sub(p.startedAt)
+	summary := RunSummary{
+		Trigger:    p.trigger,
+		StartedAt:  p.startedAt,
+		FinishedAt: finishedAt,
+		Duration:   duration,
+		DurationMs: duration.Milliseconds(),
+		Total:      snap.Total,
+		Processed:  snap.Processed,
+		Active:     snap.Active,
+		Error:      snap.Error,
+		Changed:    snap.Changed,
+		Cancelled:  cancelled,
+	}
+	if duration > 0 {
+		summary.LinksPerSecond = float64(snap.Processed) / duration.Seconds()
+	}
+	return summary
+}
+
+// Pause 暂停定时检测（不影响手动触发，重启后恢复）
+func (lc *LinkChecker) Pause() {
+	lc.mu.Lock()
+	lc.paused = true
+	lc.nextRunAt = nil
+	lc.mu.Unlock()
+	lc.logger.Info("Link checker paused")
+}
+
+// Resume 恢复定时检测
+func (lc *LinkChecker) Resume() {
+	lc.mu.Lock()
+	lc.paused = false
+	lc.mu.Unlock()
+	lc.logger.Info("Link checker resumed")
+	lc.Reload()
+}
+
+// RunNow 立即触发一次检测，已有检测在排队或进行中时返回 false
+func (lc *LinkChecker) RunNow() bool {
+	if lc.Status().Running {
+		return false
+	}
+	select {
+	case lc.trigger <- TriggerManual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reload 重新读取设置，并通知调度循环重新计算下一次运行时间
+func (lc *LinkChecker) Reload() {
+	lc.applySchedule(lc.loadSchedule())
+	select {
+	case lc.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Status 返回检测服务状态
+func (lc *LinkChecker) Status() CheckerStatus {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	status := CheckerStatus{
+		Enabled:       lc.schedule.enabled,
+		Paused:        lc.paused,
+		Running:       lc.current != nil,
+		IntervalHours: lc.schedule.interval.Hours(),
+		NextRunAt:     lc.nextRunAt,
+		LastRun:       lc.last,
+	}
+	if lc.current != nil {
+		snap := lc.current.snapshot()
+		status.Current = &snap
+	}
+	return status
+}
+
+// loop 调度循环：按设置的间隔定时检测，并响应设置变更和手动触发
+func (lc *LinkChecker) loop(ctx context.Context) {
+	lc.applySchedule(lc.loadSchedule())
+
+	// 从最近一次定时检测的时间推算下一次运行时间，避免每次重启都全量检测
+	var lastRun time.Time
+	var latest models.LinkCheckResult
+	if err := lc.db.Where("source = ?", "scheduler").Order("checked_at DESC").First(&latest).Error; err == nil {
+		lastRun = latest.CheckedAt
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	poll := time.NewTicker(settingsPollInterval)
+	defer poll.Stop()
+
+	reschedule := func() {
+		next := lc.computeNextRun(lastRun)
+		lc.mu.Lock()
+		lc.nextRunAt = next
+		lc.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		wait := time.Hour
+		if next != nil {
+			wait = time.Until(*next)
+			if wait < 0 {
+				wait = 0
+			}
+		}
+		timer.Reset(wait)
+	}
+	reschedule()
+
+	for {
+		select {
+		case <-timer.C:
+			if lc.shouldRun() {
+				lastRun = time.Now()
+				lc.runCheck(ctx, TriggerSchedule)
+			}
+			reschedule()
+		case trigger := <-lc.trigger:
+			lastRun = time.Now()
+			lc.runCheck(ctx, trigger)
+			reschedule()
+		case <-poll.C:
+			lc.applySchedule(lc.loadSchedule())
+			reschedule()
+		case <-lc.reload:
+			reschedule()
+		case <-lc.stop:
+			lc.logger.Info("Link checker stopped")
+			return
+		case <-ctx.Done():
+			lc.logger.Info("Link checker context cancelled")
+			lc.Stop()
+			return
+		}
+	}
+}
+
+// loadSchedule 从系统设置读取调度参数
+func (lc *LinkChecker) loadSchedule() checkSchedule {
+	schedule := checkSchedule{
+		enabled:  models.GetSettingBool("enable_link_check", true),
+		interval: 24 * time.Hour,
+	}
+
+	// 支持小数小时，如 0.5 表示 30 分钟
+	if hours, err := strconv.ParseFloat(models.GetSetting("check_interval_hours"), 64); err == nil && hours > 0 {
+		schedule.interval = time.Duration(hours * float64(time.Hour))
+	}
+	if schedule.interval < minCheckInterval {
+		schedule.interval = minCheckInterval
+	}
+	return schedule
+}
+
+// applySchedule 更新调度参数，变化时记录日志
+func (lc *LinkChecker) applySchedule(schedule checkSchedule) {
+	lc.mu.Lock()
+	changed := lc.schedule != schedule
+	lc.schedule = schedule
+	lc.mu.Unlock()
+
+	if changed {
+		lc.logger.Info("Link checker schedule updated",
+			zap.Bool("enabled", schedule.enabled),
+			zap.Duration("interval", schedule.interval))
+	}
+}
+
+// shouldRun 定时检测是否应该执行
+func (lc *LinkChecker) shouldRun() bool {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.schedule.enabled && !lc.paused
+}
+
+// computeNextRun 计算下一次定时检测时间，停用或暂停时返回 nil
+func (lc *LinkChecker) computeNextRun(lastRun time.Time) *time.Time {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if !lc.schedule.enabled || lc.paused {
+		return nil
+	}
+
+	next := lastRun.Add(lc.schedule.interval)
+	if now := time.Now(); next.Before(now) {
+		next = now
+	}
+	return &next
+}
@@ -0,0 +1,518 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kk-nav/internal/httpclient"
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/html"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 图标获取参数
+const (
+	faviconTimeout       = 10 * time.Second
+	faviconScanInterval  = 10 * time.Minute // 检查缺少或过期图标的间隔
+	faviconRetryAfter    = 24 * time.Hour   // 获取失败后的重试间隔
+	faviconBatchSize     = 50               // 每次检查最多获取的链接数
+	faviconConcurrency   = 4
+	faviconMaxCandidates = 6
+	maxPageBytes         = 1 << 20   // 读取的 HTML 最大字节数
+	MaxIconBytes         = 512 << 10 // 图标文件（包括上传）的最大字节数
+)
+
+// iconCandidate 候选图标
+type iconCandidate struct {
+	url  string
+	size int // 声明的尺寸，未声明时为 0
+}
+
+// FaviconService 获取链接网站的图标：解析页面中的 <link rel=icon>、Web App Manifest 的 icons 和 /favicon.ico，
+// 下载后缩放为 IconSize 的 PNG 保存到数据库。定时为缺少图标的链接获取、按 favicon_refresh_days 刷新；
+// 管理员上传的图标不会被刷新覆盖。由检测代理负责的链接（zone 不为空）服务端通常无法访问，不自动获取。
+type FaviconService struct {
+	db             *gorm.DB
+	logger         *zap.Logger
+	client         *http.Client
+	insecureClient *http.Client
+
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	stopOnce sync.Once
+	scan     chan struct{}
+}
+
+// NewFaviconService 创建图标服务
+func NewFaviconService(db *gorm.DB, logger *zap.Logger) *FaviconService {
+	opts := httpclient.Options{Timeout: faviconTimeout}
+	insecure := opts
+	insecure.InsecureSkipVerify = true
+	return &FaviconService{
+		db:             db,
+		logger:         logger,
+		client:         httpclient.New(opts),
+		insecureClient: httpclient.New(insecure),
+		stop:           make(chan struct{}),
+		scan:           make(chan struct{}, 1),
+	}
+}
+
+// Start 启动定时获取任务，开关读取系统设置 enable_favicon_fetch
+func (s *FaviconService) Start(ctx context.Context) {
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	go s.loop(runCtx)
+	s.logger.Info("Favicon service started")
+}
+
+// Stop 停止定时获取任务
+func (s *FaviconService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		if s.cancel != nil {
+			s.cancel()
+		}
+	})
+}
+
+// Scan 尽快检查一次缺少图标的链接（如新建链接或修改了地址之后）
+func (s *FaviconService) Scan() {
+	select {
+	case s.scan <- struct{}{}:
+	default:
+	}
+}
+
+func (s *FaviconService) loop(ctx context.Context) {
+	ticker := time.NewTicker(faviconScanInterval)
+	defer ticker.Stop()
+	for {
+		if models.GetSettingBool("enable_favicon_fetch", true) {
+			s.refreshStale(ctx)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.scan:
+		case <-s.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshStale 为缺少图标、图标过期或上次获取失败超过重试间隔的链接获取图标
+func (s *FaviconService) refreshStale(ctx context.Context) {
+	var links []models.Link
+	if err := s.db.Where("status <> ? AND zone = ?", models.LinkStatusInactive, "").Find(&links).Error; err != nil {
+		s.logger.Error("Failed to fetch links for favicon refresh", zap.Error(err))
+		return
+	}
+	var icons []models.LinkIcon
+	if err := s.db.Select("link_id, hash, source, fetched_at").Find(&icons).Error; err != nil {
+		s.logger.Error("Failed to fetch link icons", zap.Error(err))
+		return
+	}
+	byLink := make(map[uint]models.LinkIcon, len(icons))
+	for _, icon := range icons {
+		byLink[icon.LinkID] = icon
+	}
+
+	now := time.Now()
+	refreshAfter := time.Duration(models.GetSettingInt("favicon_refresh_days", 7)) * 24 * time.Hour
+	var due []models.Link
+	for _, link := range links {
+		icon, ok := byLink[link.ID]
+		switch {
+		case ok && icon.Source == models.IconSourceUploaded:
+			continue
+		case !ok || icon.FetchedAt == nil:
+		case icon.Hash == "" && now.Sub(*icon.FetchedAt) < faviconRetryAfter:
+			continue
+		case icon.Hash != "" && now.Sub(*icon.FetchedAt) < refreshAfter:
+			continue
+		}
+		due = append(due, link)
+		if len(due) >= faviconBatchSize {
+			break
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	s.logger.Info("Fetching link favicons", zap.Int("count", len(due)))
+	sem := make(chan struct{}, faviconConcurrency)
+	var wg sync.WaitGroup
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(link *models.Link) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.Fetch(ctx, link); err != nil {
+				s.logger.Debug("Failed to fetch favicon", zap.Uint("link_id", link.ID), zap.Error(err))
+			}
+		}(&due[i])
+	}
+	wg.Wait()
+}
+
+// Fetch 立即获取链接的图标并保存，失败时记录错误（保留已有的图标）
+func (s *FaviconService) Fetch(ctx context.Context, link *models.Link) error {
+	data, source, err := s.discover(ctx, link)
+	now := time.Now()
+	if err != nil {
+		s.db.Model(&models.LinkIcon{}).Where("link_id = ?", link.ID).Updates(map[string]interface{}{
+			"fetched_at": &now,
+			"last_error": err.Error(),
+		})
+		// 还没有记录时创建一条空记录，避免每次检查都重试
+		s.db.Where(models.LinkIcon{LinkID: link.ID}).
+			Attrs(models.LinkIcon{Source: models.IconSourceFetched, FetchedAt: &now, LastError: err.Error()}).
+			FirstOrCreate(&models.LinkIcon{})
+		return err
+	}
+	return s.save(link.ID, data, models.IconSourceFetched, source, &now)
+}
+
+// Upload 保存管理员上传的图标，之后不再自动刷新
+func (s *FaviconService) Upload(linkID uint, data []byte) error {
+	icon, err := NormalizeIcon(data)
+	if err != nil {
+		return err
+	}
+	return s.save(linkID, icon, models.IconSourceUploaded, "", nil)
+}
+
+// Reset 删除链接的图标（包括上传的），稍后重新自动获取
+func (s *FaviconService) Reset(linkID uint) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", linkID).Delete(&models.LinkIcon{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Link{}).Where("id = ?", linkID).UpdateColumn("icon_hash", "").Error
+	})
+	if err == nil {
+		s.Scan()
+	}
+	return err
+}
+
+// save 保存已规范化的 PNG 图标并更新链接的 icon_hash
+func (s *FaviconService) save(linkID uint, data []byte, source, sourceURL string, fetchedAt *time.Time) error {
+	sum := sha256.Sum256(data)
+	icon := models.LinkIcon{
+		LinkID:    linkID,
+		Data:      data,
+		Hash:      hex.EncodeToString(sum[:]),
+		Source:    source,
+		SourceURL: sourceURL,
+		FetchedAt: fetchedAt,
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "link_id"}},
+			UpdateAll: true,
+		}).Create(&icon).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Link{}).Where("id = ?", linkID).UpdateColumn("icon_hash", icon.Hash).Error
+	})
+}
+
+// discover 依次尝试候选图标，返回第一个可以解码的图标（已规范化）及其地址
+func (s *FaviconService) discover(ctx context.Context, link *models.Link) ([]byte, string, error) {
+	client := s.client
+	if link.Probe.SkipTLSVerify {
+		client = s.insecureClient
+	}
+	pageURL, err := url.Parse(link.DefaultURL())
+	if err != nil {
+		return nil, "", err
+	}
+
+	candidates, base := s.pageIcons(ctx, client, pageURL)
+	// 页面声明的图标之后尝试站点根目录的 /favicon.ico（跳转后的站点优先）
+	for _, u := range []*url.URL{base, pageURL} {
+		candidates = append(candidates, iconCandidate{url: u.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()})
+	}
+
+	seen := map[string]bool{}
+	tried := 0
+	lastErr := errors.New("no icon found")
+	for _, c := range candidates {
+		if seen[c.url] || tried >= faviconMaxCandidates {
+			continue
+		}
+		seen[c.url] = true
+		tried++
+
+		raw, err := s.download(ctx, client, c.url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		icon, err := NormalizeIcon(raw)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", c.url, err)
+			continue
+		}
+		source := c.url
+		if strings.HasPrefix(source, "data:") {
+			source = "data:"
+		}
+		return icon, source, nil
+	}
+	return nil, "", lastErr
+}
+
+// pageIcons 解析页面 <head> 中声明的图标和 Manifest 中的图标，按尺寸排序；返回跳转后的页面地址作为基准地址
+func (s *FaviconService) pageIcons(ctx context.Context, client *http.Client, pageURL *url.URL) ([]iconCandidate, *url.URL) {
+	resp, err := s.get(ctx, client, pageURL.String(), "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, pageURL
+	}
+	defer resp.Body.Close()
+	base := resp.Request.URL
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, base
+	}
+
+	var candidates []iconCandidate
+	var manifest string
+	z := html.NewTokenizer(io.LimitReader(resp.Body, maxPageBytes))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken && tt != html.EndTagToken {
+			continue
+		}
+		name, hasAttr := z.TagName()
+		tag := string(name)
+		if tag == "body" || (tt == html.EndTagToken && tag == "head") {
+			break
+		}
+		if tt == html.EndTagToken || !hasAttr || (tag != "link" && tag != "base") {
+			continue
+		}
+
+		attrs := map[string]string{}
+		for {
+			key, val, more := z.TagAttr()
+			attrs[string(key)] = string(val)
+			if !more {
+				break
+			}
+		}
+		href := strings.TrimSpace(attrs["href"])
+		if href == "" {
+			continue
+		}
+		if tag == "base" {
+			if u, err := base.Parse(href); err == nil {
+				base = u
+			}
+			continue
+		}
+
+		rels := strings.Fields(strings.ToLower(attrs["rel"]))
+		switch {
+		case containsAny(rels, "manifest"):
+			manifest = href
+		case containsAny(rels, "icon", "apple-touch-icon", "apple-touch-icon-precomposed"):
+			if isSVG(attrs["type"], href) {
+				continue
+			}
+			if u := resolveIcon(base, href); u != "" {
+				candidates = append(candidates, iconCandidate{url: u, size: largestSize(attrs["sizes"])})
+			}
+		}
+	}
+
+	if manifest != "" {
+		if u, err := base.Parse(manifest); err == nil {
+			candidates = append(candidates, s.manifestIcons(ctx, client, u)...)
+		}
+	}
+	sortCandidates(candidates)
+	return candidates, base
+}
+
+// manifestIcons 读取 Web App Manifest 中的 icons
+func (s *FaviconService) manifestIcons(ctx context.Context, client *http.Client, manifestURL *url.URL) []iconCandidate {
+	raw, err := s.download(ctx, client, manifestURL.String())
+	if err != nil {
+		return nil
+	}
+	var manifest struct {
+		Icons []struct {
+			Src   string `json:"src"`
+			Sizes string `json:"sizes"`
+			Type  string `json:"type"`
+		} `json:"icons"`
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil
+	}
+	var candidates []iconCandidate
+	for _, icon := range manifest.Icons {
+		if icon.Src == "" || isSVG(icon.Type, icon.Src) {
+			continue
+		}
+		if u := resolveIcon(manifestURL, icon.Src); u != "" {
+			candidates = append(candidates, iconCandidate{url: u, size: largestSize(icon.Sizes)})
+		}
+	}
+	return candidates
+}
+
+// download 下载图标或 Manifest，支持 data: URI，超过 MaxIconBytes 时报错
+func (s *FaviconService) download(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	if strings.HasPrefix(rawURL, "data:") {
+		return decodeDataURI(rawURL)
+	}
+	resp, err := s.get(ctx, client, rawURL, "image/*,application/manifest+json,*/*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxIconBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxIconBytes {
+		return nil, fmt.Errorf("%s: icon too large", rawURL)
+	}
+	return data, nil
+}
+
+// get 发送 GET 请求，非 2xx 响应视为失败
+func (s *FaviconService) get(ctx context.Context, client *http.Client, rawURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// resolveIcon 把图标地址解析为绝对地址，只接受 http(s) 和 data: 图片
+func resolveIcon(base *url.URL, href string) string {
+	if strings.HasPrefix(href, "data:image/") {
+		return href
+	}
+	u, err := base.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// decodeDataURI 解码 base64 编码的 data: URI
+func decodeDataURI(uri string) ([]byte, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return nil, errors.New("unsupported data URI")
+	}
+	if base64.StdEncoding.DecodedLen(len(payload)) > MaxIconBytes {
+		return nil, errors.New("data URI icon too large")
+	}
+	return base64.StdEncoding.DecodeString(payload)
+}
+
+// sortCandidates 优先选择不小于 IconSize 的最小图标，其次是尽量大的图标；未声明尺寸的按 32 像素处理
+func sortCandidates(candidates []iconCandidate) {
+	rank := func(c iconCandidate) (int, int) {
+		size := c.size
+		if size == 0 {
+			size = 32
+		}
+		if size >= IconSize {
+			return 0, size
+		}
+		return 1, -size
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		gi, si := rank(candidates[i])
+		gj, sj := rank(candidates[j])
+		if gi != gj {
+			return gi < gj
+		}
+		return si < sj
+	})
+}
+
+// largestSize 解析 sizes 属性（如 "16x16 32x32"），返回最大边长；any 或无法解析时为 0
+func largestSize(sizes string) int {
+	largest := 0
+	for _, s := range strings.Fields(strings.ToLower(sizes)) {
+		w, _, ok := strings.Cut(s, "x")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil && n > largest {
+			largest = n
+		}
+	}
+	return largest
+}
+
+// isSVG 无法栅格化 SVG 图标，跳过
+func isSVG(mime, href string) bool {
+	if strings.Contains(strings.ToLower(mime), "svg") {
+		return true
+	}
+	path := strings.ToLower(href)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	return strings.HasSuffix(path, ".svg") || strings.HasPrefix(path, "data:image/svg")
+}
+
+func containsAny(items []string, values ...string) bool {
+	for _, item := range items {
+		for _, v := range values {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
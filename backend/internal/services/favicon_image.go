@@ -0,0 +1,255 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"image"
+	"image/color"
+	_ "image/gif"  // 注册 GIF 解码器
+	_ "image/jpeg" // 注册 JPEG 解码器
+	"image/png"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IconSize 保存的图标边长（像素）
+const IconSize = 64
+
+// maxIconDimension 可以解码的最大图片边长，防止超大图片占用过多内存
+const maxIconDimension = 2048
+
+var (
+	errUnsupportedIcon = errors.New("unsupported image format")
+	pngSignature       = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// NormalizeIcon 解码图标（PNG、JPEG、GIF 或 ICO），等比缩放到 IconSize 的透明正方形画布并编码为 PNG
+func NormalizeIcon(data []byte) ([]byte, error) {
+	img, err := decodeIcon(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resizeIcon(img, IconSize)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeIcon 按内容识别格式并解码
+func decodeIcon(data []byte) (image.Image, error) {
+	if isICO(data) {
+		return decodeICO(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupportedIcon
+	}
+	if cfg.Width > maxIconDimension || cfg.Height > maxIconDimension {
+		return nil, fmt.Errorf("image too large: %dx%d", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// resizeIcon 等比缩放到 size×size 的透明画布中央：缩小时按区域取平均，放大时取最近的像素
+func resizeIcon(src image.Image, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 {
+		return dst
+	}
+
+	// 目标区域（保持宽高比）
+	dw, dh := size, size
+	if sw > sh {
+		dh = max(1, size*sh/sw)
+	} else if sh > sw {
+		dw = max(1, size*sw/sh)
+	}
+	ox, oy := (size-dw)/2, (size-dh)/2
+
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := b.Min.Y+y*sh/dh, b.Min.Y+(y+1)*sh/dh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := b.Min.X+x*sw/dw, b.Min.X+(x+1)*sw/dw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			// 按预乘 alpha 累加，避免透明像素的颜色渗入边缘
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			c := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)}
+			dst.Set(ox+x, oy+y, c)
+		}
+	}
+	return dst
+}
+
+// isICO 判断是否为 ICO 文件（保留字 0，类型 1）
+func isICO(data []byte) bool {
+	return len(data) >= 6 && binary.LittleEndian.Uint16(data[0:]) == 0 && binary.LittleEndian.Uint16(data[2:]) == 1
+}
+
+// decodeICO 选择 ICO 中尺寸最大、色深最高的图片并解码，支持内嵌 PNG 和 1/4/8/24/32 位 BMP
+func decodeICO(data []byte) (image.Image, error) {
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	if count == 0 || len(data) < 6+16*count {
+		return nil, errors.New("invalid ICO header")
+	}
+
+	best, bestSize, bestBpp := -1, 0, 0
+	for i := 0; i < count; i++ {
+		e := data[6+16*i:]
+		size := int(e[0])
+		if size == 0 {
+			size = 256
+		}
+		bpp := int(binary.LittleEndian.Uint16(e[6:]))
+		if size > bestSize || (size == bestSize && bpp > bestBpp) {
+			best, bestSize, bestBpp = i, size, bpp
+		}
+	}
+
+	e := data[6+16*best:]
+	length := int(binary.LittleEndian.Uint32(e[8:]))
+	offset := int(binary.LittleEndian.Uint32(e[12:]))
+	if offset < 0 || length <= 0 || offset+length > len(data) || offset+length < offset {
+		return nil, errors.New("invalid ICO entry")
+	}
+	entry := data[offset : offset+length]
+	if bytes.HasPrefix(entry, pngSignature) {
+		return decodeIcon(entry)
+	}
+	return decodeDIB(entry)
+}
+
+// decodeDIB 解码 ICO 中的 BMP 图片：高度包含 XOR 位图和 AND 透明掩码两部分，行从下往上存储
+func decodeDIB(data []byte) (image.Image, error) {
+	if len(data) < 40 {
+		return nil, errors.New("invalid ICO bitmap")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:]))) / 2
+	bpp := int(binary.LittleEndian.Uint16(data[14:]))
+	compression := binary.LittleEndian.Uint32(data[16:])
+	colorsUsed := int(binary.LittleEndian.Uint32(data[32:]))
+	if width <= 0 || height <= 0 || width > 256 || height > 256 || compression != 0 || headerSize < 40 || headerSize > len(data) {
+		return nil, errUnsupportedIcon
+	}
+
+	var palette []color.NRGBA
+	pos := headerSize
+	switch bpp {
+	case 1, 4, 8:
+		n := colorsUsed
+		if n == 0 {
+			n = 1 << bpp
+		}
+		if pos+4*n > len(data) {
+			return nil, errors.New("invalid ICO palette")
+		}
+		palette = make([]color.NRGBA, n)
+		for i := range palette {
+			p := data[pos+4*i:]
+			palette[i] = color.NRGBA{R: p[2], G: p[1], B: p[0], A: 0xff}
+		}
+		pos += 4 * n
+	case 24, 32:
+	default:
+		return nil, errUnsupportedIcon
+	}
+
+	stride := (width*bpp + 31) / 32 * 4
+	maskStride := (width + 31) / 32 * 4
+	if pos+stride*height > len(data) {
+		return nil, errors.New("truncated ICO bitmap")
+	}
+	mask := data[pos+stride*height:]
+	hasMask := len(mask) >= maskStride*height
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	anyAlpha := false
+	for y := 0; y < height; y++ {
+		row := data[pos+(height-1-y)*stride:]
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+			switch bpp {
+			case 32:
+				c = color.NRGBA{R: row[4*x+2], G: row[4*x+1], B: row[4*x], A: row[4*x+3]}
+				anyAlpha = anyAlpha || c.A != 0
+			case 24:
+				c = color.NRGBA{R: row[3*x+2], G: row[3*x+1], B: row[3*x], A: 0xff}
+			default:
+				bit := x * bpp
+				index := int(row[bit/8]>>(8-bpp-bit%8)) & (1<<bpp - 1)
+				if index < len(palette) {
+					c = palette[index]
+				}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	// 没有 alpha 通道时使用 AND 掩码：置位的像素透明
+	if bpp == 32 && anyAlpha {
+		return img, nil
+	}
+	for y := 0; hasMask && y < height; y++ {
+		row := mask[(height-1-y)*maskStride:]
+		for x := 0; x < width; x++ {
+			c := img.NRGBAAt(x, y)
+			if row[x/8]&(0x80>>(x%8)) != 0 {
+				c.A = 0
+			} else {
+				c.A = 0xff
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+// letterColors 字母图标的背景色
+var letterColors = []string{
+	"#1e88e5", "#43a047", "#e53935", "#8e24aa", "#fb8c00",
+	"#00897b", "#3949ab", "#d81b60", "#6d4c41", "#546e7a",
+}
+
+// LetterIcon 生成以标题首字符为内容的 SVG 图标，背景色由标题决定
+func LetterIcon(title string) []byte {
+	title = strings.TrimSpace(title)
+	letter := "?"
+	if r, _ := utf8.DecodeRuneInString(title); r != utf8.RuneError && !unicode.IsSpace(r) {
+		letter = string(unicode.ToUpper(r))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(title))
+	bg := letterColors[h.Sum32()%uint32(len(letterColors))]
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 64 64">`+
+		`<rect width="64" height="64" rx="12" fill="%[2]s"/>`+
+		`<text x="32" y="32" dy=".35em" text-anchor="middle" font-family="-apple-system,'Segoe UI',Roboto,'PingFang SC','Microsoft YaHei',sans-serif" font-size="34" font-weight="600" fill="#fff">%[3]s</text>`+
+		`</svg>`, IconSize, bg, html.EscapeString(letter)))
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// icoImage ICO 目录中的一项
+type icoImage struct {
+	size byte // 0 表示 256
+	bpp  uint16
+	data []byte
+}
+
+// buildICO 按目录顺序拼接 ICO 文件
+func buildICO(images ...icoImage) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, uint16(len(images))})
+	offset := 6 + 16*len(images)
+	for _, img := range images {
+		buf.Write([]byte{img.size, img.size, 0, 0})
+		binary.Write(&buf, binary.LittleEndian, [2]uint16{1, img.bpp})
+		binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(img.data)), uint32(offset)})
+		offset += len(img.data)
+	}
+	for _, img := range images {
+		buf.Write(img.data)
+	}
+	return buf.Bytes()
+}
+
+// buildDIB 生成 ICO 中的 BMP 图片；rows 和 maskRows 按从上到下的顺序给出，未补齐到 4 字节
+func buildDIB(width, height, bpp int, palette []color.NRGBA, rows, maskRows [][]byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{40, uint32(width), uint32(height * 2)})
+	binary.Write(&buf, binary.LittleEndian, []uint16{1, uint16(bpp)})
+	binary.Write(&buf, binary.LittleEndian, []uint32{0, 0, 0, 0, uint32(len(palette)), 0})
+	for _, c := range palette {
+		buf.Write([]byte{c.B, c.G, c.R, 0})
+	}
+	writeRows := func(rows [][]byte, stride int) {
+		for y := len(rows) - 1; y >= 0; y-- {
+			row := make([]byte, stride)
+			copy(row, rows[y])
+			buf.Write(row)
+		}
+	}
+	writeRows(rows, (width*bpp+31)/32*4)
+	writeRows(maskRows, (width+31)/32*4)
+	return buf.Bytes()
+}
+
+func buildPNG(width, height int, c color.NRGBA) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestDecodeICO(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+	green := color.NRGBA{G: 0xff, A: 0xff}
+	blue := color.NRGBA{B: 0xff, A: 0xff}
+	transparent := color.NRGBA{}
+
+	// 2×2 的 32 位图片：左上红色不透明，右上绿色半透明，下方一行透明
+	dib32 := buildDIB(2, 2, 32, nil, [][]byte{
+		{0, 0, 0xff, 0xff, 0, 0xff, 0, 0x80},
+		{0, 0, 0, 0, 0, 0, 0, 0},
+	}, [][]byte{{0}, {0}})
+	// 2×2 的 24 位图片：AND 掩码让右下角透明
+	dib24 := buildDIB(2, 2, 24, nil, [][]byte{
+		{0, 0, 0xff, 0, 0xff, 0},
+		{0xff, 0, 0, 0, 0, 0xff},
+	}, [][]byte{{0x00}, {0x40}})
+	// 3×1 的 1 位调色板图片
+	dib1 := buildDIB(3, 1, 1, []color.NRGBA{red, blue}, [][]byte{{0x40}}, [][]byte{{0}})
+	// 2×2 的 8 位调色板图片，与 dib32 同尺寸但色深更低
+	dib8 := buildDIB(2, 2, 8, []color.NRGBA{blue}, [][]byte{{0, 0}, {0, 0}}, [][]byte{{0}, {0}})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		size    image.Point
+		pixels  map[image.Point]color.NRGBA
+		wantErr bool
+	}{
+		{
+			name: "32-bit with alpha", data: buildICO(icoImage{2, 32, dib32}), size: image.Pt(2, 2),
+			pixels: map[image.Point]color.NRGBA{
+				{0, 0}: red, {1, 0}: {G: 0xff, A: 0x80}, {0, 1}: transparent,
+			},
+		},
+		{
+			name: "24-bit with AND mask", data: buildICO(icoImage{2, 24, dib24}), size: image.Pt(2, 2),
+			pixels: map[image.Point]color.NRGBA{
+				{0, 0}: red, {1, 0}: green, {0, 1}: blue, {1, 1}: {R: 0xff, A: 0},
+			},
+		},
+		{
+			name: "1-bit palette", data: buildICO(icoImage{3, 1, dib1}), size: image.Pt(3, 1),
+			pixels: map[image.Point]color.NRGBA{{0, 0}: red, {1, 0}: blue, {2, 0}: red},
+		},
+		{
+			name: "embedded PNG", data: buildICO(icoImage{4, 32, buildPNG(4, 4, green)}), size: image.Pt(4, 4),
+			pixels: map[image.Point]color.NRGBA{{3, 3}: green},
+		},
+		{
+			name: "largest image wins", data: buildICO(icoImage{2, 32, dib32}, icoImage{4, 32, buildPNG(4, 4, green)}),
+			size: image.Pt(4, 4),
+		},
+		{
+			name: "size 0 means 256", data: buildICO(icoImage{0, 32, buildPNG(3, 3, green)}, icoImage{4, 32, buildPNG(4, 4, blue)}),
+			size: image.Pt(3, 3),
+		},
+		{
+			name: "higher bit depth wins at the same size", data: buildICO(icoImage{2, 8, dib8}, icoImage{2, 32, dib32}),
+			size: image.Pt(2, 2), pixels: map[image.Point]color.NRGBA{{0, 0}: red},
+		},
+		{name: "no images", data: buildICO(), wantErr: true},
+		{name: "truncated directory", data: buildICO(icoImage{2, 32, dib32})[:10], wantErr: true},
+		{name: "entry past end of file", data: buildICO(icoImage{2, 32, dib32})[:30], wantErr: true},
+		{name: "truncated bitmap", data: buildICO(icoImage{2, 32, dib32[:48]}), wantErr: true},
+		{name: "unsupported bit depth", data: buildICO(icoImage{2, 16, buildDIB(2, 2, 16, nil, nil, nil)}), wantErr: true},
+		{name: "invalid PNG", data: buildICO(icoImage{2, 32, append(append([]byte{}, pngSignature...), 0, 0, 0)}), wantErr: true},
+	}
+	for _, tt := range tests {
+		img, err := decodeICO(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := img.Bounds().Size(); got != tt.size {
+			t.Errorf("%s: size = %v, want %v", tt.name, got, tt.size)
+			continue
+		}
+		for p, want := range tt.pixels {
+			if got := color.NRGBAModel.Convert(img.At(p.X, p.Y)).(color.NRGBA); got != want {
+				t.Errorf("%s: pixel %v = %v, want %v", tt.name, p, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter 按主机限制并发数和请求速率，避免一次检测压垮同一台服务器
+type hostLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	interval time.Duration
+	hosts    map[string]*hostSlot
+}
+
+type hostSlot struct {
+	sem  chan struct{}
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newHostLimiter(limit int, interval time.Duration) *hostLimiter {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &hostLimiter{
+		limit:    limit,
+		interval: interval,
+		hosts:    make(map[string]*hostSlot),
+	}
+}
+
+// acquire 获取主机的检测配额，返回的 release 必须在请求结束后调用
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	slot := l.slot(host)
+
+	select {
+	case slot.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-slot.sem }
+
+	if l.interval > 0 {
+		slot.mu.Lock()
+		now := time.Now()
+		start := slot.next
+		if start.Before(now) {
+			start = now
+		}
+		slot.next = start.Add(l.interval)
+		slot.mu.Unlock()
+
+		if wait := start.Sub(now); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+func (l *hostLimiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, l.limit)}
+		l.hosts[host] = slot
+	}
+	return slot
+}
+
+// hostOf 提取URL中的主机名（含端口），解析失败时返回原始字符串
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Host)
+}
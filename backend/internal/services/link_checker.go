@@ -7,140 +7,449 @@ package services
 
 import (
 	"context"
-	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"kk-nav/internal/config"
+	"kk-nav/internal/metrics"
 	"kk-nav/internal/models"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // LinkChecker 链接状态检测服务
 type LinkChecker struct {
-	db     *gorm.DB
-	logger *zap.Logger
-	ticker *time.Ticker
-	stop   chan struct{}
+	db       *gorm.DB
+	logger   *zap.Logger
+	cfg      config.CheckerConfig
+	prober   *Prober
+	notifier *Notifier
+	cancel   context.CancelFunc
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// 调度控制
+	trigger chan string
+	reload  chan struct{}
+
+	mu        sync.Mutex
+	schedule  checkSchedule
+	paused    bool
+	nextRunAt *time.Time
+	current   *runProgress
+	last      *RunSummary
+
+	// 后台批量检测任务
+	jobsMu sync.Mutex
+	jobs   map[string]*checkJob
 }
 
-// NewLinkChecker 创建链接检测服务
-func NewLinkChecker(db *gorm.DB, logger *zap.Logger) *LinkChecker {
+// NewLinkChecker 创建链接检测服务，notifier 为空时不发送状态变更通知
+func NewLinkChecker(db *gorm.DB, logger *zap.Logger, cfg config.CheckerConfig, notifier *Notifier) *LinkChecker {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
 	return &LinkChecker{
-		db:     db,
-		logger: logger,
-		stop:   make(chan struct{}),
+		db:       db,
+		logger:   logger,
+		cfg:      cfg,
+		prober:   NewProber(cfg),
+		notifier: notifier,
+		stop:     make(chan struct{}),
+		trigger:  make(chan string, 1),
+		reload:   make(chan struct{}, 1),
+		jobs:     make(map[string]*checkJob),
 	}
 }
 
-// Start 启动定时检测任务（每1小时运行一次）
+// Start 启动定时检测任务，开关和间隔读取系统设置 enable_link_check / check_interval_hours
 func (lc *LinkChecker) Start(ctx context.Context) {
-	// 立即运行一次
-	go lc.runCheck()
-
-	// 每1小时运行一次
-	lc.ticker = time.NewTicker(1 * time.Hour)
-
-	go func() {
-		for {
-			select {
-			case <-lc.ticker.C:
-				lc.runCheck()
-			case <-lc.stop:
-				lc.logger.Info("Link checker stopped")
-				return
-			case <-ctx.Done():
-				lc.logger.Info("Link checker context cancelled")
-				lc.Stop()
-				return
-			}
-		}
-	}()
+	runCtx, cancel := context.WithCancel(ctx)
+	lc.cancel = cancel
+
+	go lc.loop(runCtx)
 
-	lc.logger.Info("Link checker started, will run every 1 hour")
+	lc.logger.Info("Link checker started",
+		zap.Int("concurrency", lc.cfg.Concurrency),
+		zap.Int("per_host_concurrency", lc.cfg.PerHostConcurrency),
+		zap.Duration("per_host_interval", lc.cfg.PerHostInterval))
 }
 
-// Stop 停止定时检测任务
+// Stop 停止定时检测任务，并取消正在进行的检测
 func (lc *LinkChecker) Stop() {
-	if lc.ticker != nil {
-		lc.ticker.Stop()
-	}
-	close(lc.stop)
+	lc.stopOnce.Do(func() {
+		close(lc.stop)
+		if lc.cancel != nil {
+			lc.cancel()
+		}
+	})
 }
 
 // runCheck 执行链接状态检测
-func (lc *LinkChecker) runCheck() {
-	lc.logger.Info("Starting link status check job")
+func (lc *LinkChecker) runCheck(ctx context.Context, trigger string) {
+	lc.logger.Info("Starting link status check job", zap.String("trigger", trigger))
+	start := time.Now()
 
-	// 只检测状态为 active 或 error 的链接，跳过 inactive（手动禁用）
+	// 跳过 inactive（手动禁用）的链接，以及由检测代理负责的链接
 	var links []models.Link
-	if err := lc.db.Where("status IN ?", []string{"active", "error"}).Find(&links).Error; err != nil {
+	if err := lc.db.Where("status IN ? AND zone = ?", models.CheckedLinkStatuses, "").Find(&links).Error; err != nil {
 		lc.logger.Error("Failed to fetch links for status check", zap.Error(err))
 		return
 	}
 
+	// 维护窗口：skip 的链接不检测，freeze 的链接只记录历史
+	maintenance := lc.maintenance(start)
+	if len(maintenance) > 0 {
+		checked := links[:0]
+		for _, link := range links {
+			if w := maintenance.For(link.ID); w == nil || w.Mode != models.MaintenanceSkip {
+				checked = append(checked, link)
+			}
+		}
+		if skipped := len(links) - len(checked); skipped > 0 {
+			lc.logger.Info("Skipping links in maintenance", zap.Int("count", skipped))
+		}
+		links = checked
+	}
+
+	progress := &runProgress{trigger: trigger, startedAt: start, total: int64(len(links))}
+	lc.mu.Lock()
+	lc.current = progress
+	lc.mu.Unlock()
+
 	if len(links) == 0 {
 		lc.logger.Info("No links to check")
+	} else {
+		lc.logger.Info("Checking links", zap.Int("count", len(links)))
+	}
+
+	policy := loadPolicy()
+	events := &eventBuffer{}
+	lc.checkLinks(ctx, links, policy, func(link models.Link, result ProbeResult) {
+		lc.recordResult(link.ID, result, "scheduler")
+		from := link.Status
+		if maintenance.For(link.ID) == nil {
+			lc.mirrorEndpoints(&link, result)
+			if lc.saveResult(&link, result, policy, false) {
+				atomic.AddInt64(&progress.changed, 1)
+				events.add(&link, from, result)
+			}
+		}
+
+		if result.Status == "active" {
+			atomic.AddInt64(&progress.active, 1)
+		} else {
+			atomic.AddInt64(&progress.errored, 1)
+		}
+	})
+	lc.checkEndpoints(ctx, maintenance.Unaffected(links), policy)
+
+	summary := progress.summary(time.Now(), ctx.Err() != nil)
+	lc.mu.Lock()
+	lc.current = nil
+	lc.last = &summary
+	lc.mu.Unlock()
+
+	lc.logger.Info("Link status check completed",
+		zap.String("trigger", trigger),
+		zap.Int64("total", summary.Total),
+		zap.Int64("processed", summary.Processed),
+		zap.Int64("changed", summary.Changed),
+		zap.Int64("active", summary.Active),
+		zap.Int64("error", summary.Error),
+		zap.Duration("duration", summary.Duration),
+		zap.Float64("links_per_second", summary.LinksPerSecond),
+		zap.Bool("cancelled", summary.Cancelled))
+
+	metrics.ObserveCheckerRun(trigger, summary.Duration, summary.Active, summary.Error, summary.Cancelled)
+	lc.notify(events.events)
+	lc.pruneHistory()
+}
+
+// CheckLink 立即检测单个链接并保存结果（管理后台手动检测，结果直接生效，不受阈值限制）
+func (lc *LinkChecker) CheckLink(ctx context.Context, link *models.Link) ProbeResult {
+	policy := loadPolicy()
+	result := lc.probe(ctx, link, policy.forLink(link))
+	lc.recordResult(link.ID, result, "manual")
+	if lc.maintenance(time.Now()).For(link.ID) != nil {
+		// 维护期间只记录检测历史，不改变状态
+		return result
+	}
+	lc.mirrorEndpoints(link, result)
+	lc.checkEndpoints(ctx, []models.Link{*link}, policy)
+	from := link.Status
+	if lc.saveResult(link, result, policy, true) {
+		events := &eventBuffer{}
+		events.add(link, from, result)
+		lc.notify(events.events)
+	}
+	return result
+}
+
+// CheckLinks 并发检测多个链接并保存结果（手动检测），每个结果保存后通过 fn 回调（可能被并发调用）
+func (lc *LinkChecker) CheckLinks(ctx context.Context, links []models.Link, fn func(models.Link, ProbeResult)) {
+	policy := loadPolicy()
+	maintenance := lc.maintenance(time.Now())
+	events := &eventBuffer{}
+	lc.checkLinks(ctx, links, policy, func(link models.Link, result ProbeResult) {
+		lc.recordResult(link.ID, result, "manual")
+		from := link.Status
+		if maintenance.For(link.ID) == nil {
+			lc.mirrorEndpoints(&link, result)
+			if lc.saveResult(&link, result, policy, true) {
+				events.add(&link, from, result)
+			}
+		}
+		fn(link, result)
+	})
+	lc.checkEndpoints(ctx, maintenance.Unaffected(links), policy)
+	lc.notify(events.events)
+}
+
+// maintenance 查询当前生效的维护窗口，查询失败时按没有维护窗口处理
+func (lc *LinkChecker) maintenance(now time.Time) models.MaintenanceIndex {
+	index, err := models.ActiveMaintenance(lc.db, now)
+	if err != nil {
+		lc.logger.Error("Failed to load maintenance windows", zap.Error(err))
+		return nil
+	}
+	return index
+}
+
+// notify 在后台发送状态变更汇总通知
+func (lc *LinkChecker) notify(events []StatusEvent) {
+	if lc.notifier == nil || len(events) == 0 {
 		return
 	}
+	go lc.notifier.Notify(context.Background(), events)
+}
+
+// checkLinks 使用有界工作池并发检测链接，每个结果通过 fn 回调（可能被并发调用）
+func (lc *LinkChecker) checkLinks(ctx context.Context, links []models.Link, policy checkPolicy, fn func(models.Link, ProbeResult)) {
+	targets := make([]probeTarget, len(links))
+	for i := range links {
+		targets[i] = probeTarget{url: links[i].DefaultURL(), probe: links[i].Probe, policy: policy.forLink(&links[i])}
+	}
+	probePool(ctx, lc.prober, lc.cfg, targets, func(i int, result ProbeResult) {
+		fn(links[i], result)
+	})
+}
+
+// probe 探测单个链接，失败时按策略重试
+func (lc *LinkChecker) probe(ctx context.Context, link *models.Link, policy checkPolicy) ProbeResult {
+	result, _ := probeWithRetry(ctx, lc.prober, nil, probeTarget{url: link.DefaultURL(), probe: link.Probe, policy: policy})
+	return result
+}
+
+// probeTarget 待探测的目标
+type probeTarget struct {
+	url    string
+	probe  models.LinkProbe
+	policy checkPolicy
+}
+
+// probePool 使用有界工作池并发探测，每个结果通过 fn 回调（可能被并发调用），i 为 targets 下标
+func probePool(ctx context.Context, prober *Prober, cfg config.CheckerConfig, targets []probeTarget, fn func(i int, result ProbeResult)) {
+	limiter := newHostLimiter(cfg.PerHostConcurrency, cfg.PerHostInterval)
+	jobs := make(chan int)
+
+	workers := cfg.Concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(targets) {
+		workers = len(targets)
+	}
 
-	lc.logger.Info("Checking links", zap.Int("count", len(links)))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, err := probeWithRetry(ctx, prober, limiter, targets[i])
+				if err != nil {
+					continue
+				}
 
-	now := time.Now()
-	checkedCount := 0
-	activeCount := 0
-	errorCount := 0
+				// 任务被取消时丢弃未完成的结果，避免把链接误标为 error
+				if ctx.Err() != nil {
+					continue
+				}
+				fn(i, result)
+			}
+		}()
+	}
 
-	for _, link := range links {
-		status := lc.checkLinkStatus(link.URL)
+feed:
+	for i := range targets {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
 
-		// 只更新状态为 active 或 error，不改变 inactive
-		if link.Status != status {
-			link.Status = status
-			link.LastCheckedAt = &now
-			if err := lc.db.Save(&link).Error; err != nil {
-				lc.logger.Error("Failed to update link status",
-					zap.Uint("link_id", link.ID),
-					zap.String("url", link.URL),
-					zap.Error(err))
-				continue
+// probeWithRetry 探测目标，失败时按策略指数退避重试；每次请求前获取主机限流许可（limiter 为 nil 时不限流）
+func probeWithRetry(ctx context.Context, prober *Prober, limiter *hostLimiter, target probeTarget) (ProbeResult, error) {
+	backoff := target.policy.retryBackoff
+	for attempt := 0; ; attempt++ {
+		release := func() {}
+		if limiter != nil {
+			var err error
+			if release, err = limiter.acquire(ctx, hostOf(target.url)); err != nil {
+				return ProbeResult{}, err
 			}
-			checkedCount++
-		} else {
-			// 即使状态没变，也更新检测时间
-			link.LastCheckedAt = &now
-			lc.db.Model(&link).Update("last_checked_at", now)
 		}
+		result := prober.Probe(ctx, target.url, target.probe)
+		release()
 
-		if status == "active" {
-			activeCount++
-		} else {
-			errorCount++
+		if result.Status == models.LinkStatusActive || attempt >= target.policy.retries || !retryable(result) {
+			return result, nil
 		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result, nil
+		}
+		backoff *= 2
 	}
+}
 
-	lc.logger.Info("Link status check completed",
-		zap.Int("total", len(links)),
-		zap.Int("checked", checkedCount),
-		zap.Int("active", activeCount),
-		zap.Int("error", errorCount))
+// retryable 配置错误重试也不会成功
+func retryable(result ProbeResult) bool {
+	return result.ErrorClass != models.ErrorClassInvalidConfig && result.ErrorClass != models.ErrorClassInvalidURL
 }
 
-// checkLinkStatus 检测单个链接的状态
-func (lc *LinkChecker) checkLinkStatus(url string) string {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// recordResult 写入检测历史
+func (lc *LinkChecker) recordResult(linkID uint, result ProbeResult, source string) {
+	record := models.LinkCheckResult{
+		LinkID:     linkID,
+		Status:     result.Status,
+		StatusCode: result.StatusCode,
+		LatencyMs:  result.Latency.Milliseconds(),
+		ErrorClass: result.ErrorClass,
+		Error:      result.ErrorMessage(),
+		FinalURL:   result.FinalURL,
+		Source:     source,
+		CheckedAt:  result.CheckedAt,
+	}
+	if err := lc.db.Create(&record).Error; err != nil {
+		lc.logger.Error("Failed to record link check result",
+			zap.Uint("link_id", linkID),
+			zap.Error(err))
 	}
+}
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return "error"
+// pruneHistory 清理超过保留天数的检测历史
+func (lc *LinkChecker) pruneHistory() {
+	days := models.GetSettingInt("check_history_days", 30)
+	if days <= 0 {
+		return
 	}
-	defer resp.Body.Close()
 
-	// HTTP 状态码 200-399 视为正常
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return "active"
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := lc.db.Where("checked_at < ?", cutoff).Delete(&models.LinkCheckResult{})
+	if result.Error != nil {
+		lc.logger.Error("Failed to prune link check history", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		lc.logger.Info("Pruned link check history", zap.Int64("deleted", result.RowsAffected))
 	}
-	return "error"
 }
 
+// saveResult 保存检测结果，返回状态是否发生变化。
+// 定时检测按连续失败/成功阈值切换状态，force 为 true 时（手动检测）直接采用本次结果。
+func (lc *LinkChecker) saveResult(link *models.Link, result ProbeResult, policy checkPolicy, force bool) bool {
+	now := result.CheckedAt
+	previous := link.Status
+	up := result.Status == models.LinkStatusActive
+
+	if up {
+		link.ConsecutiveSuccesses++
+		link.ConsecutiveFailures = 0
+	} else {
+		link.ConsecutiveFailures++
+		link.ConsecutiveSuccesses = 0
+	}
+
+	if force {
+		link.Status = result.Status
+	} else {
+		link.Status = policy.forLink(link).nextStatus(link, up)
+	}
+	changed := link.Status != previous
+
+	link.LastCheckedAt = &now
+	link.LastStatusCode = result.StatusCode
+	link.LastErrorClass = result.ErrorClass
+	link.LastError = result.ErrorMessage()
+	columns := []string{"status", "last_checked_at", "last_status_code", "last_error_class", "last_error",
+		"consecutive_failures", "consecutive_successes"}
+
+	if cert := result.Certificate; cert != nil {
+		notAfter := cert.NotAfter
+		match := cert.HostnameMatch
+		link.Certificate = models.LinkCertificate{
+			ExpiresAt:     &notAfter,
+			Issuer:        cert.Issuer,
+			Subject:       cert.Subject,
+			HostnameMatch: &match,
+			CheckedAt:     &now,
+		}
+		columns = append(columns, "cert_expires_at", "cert_issuer", "cert_subject", "cert_hostname_match", "cert_checked_at")
+
+		if link.Certificate.IsExpiring(now, policy.certWarningDays) || !match {
+			lc.logger.Warn("Link certificate needs attention",
+				zap.Uint("link_id", link.ID),
+				zap.String("url", link.URL),
+				zap.Time("expires_at", notAfter),
+				zap.Bool("hostname_match", match))
+		}
+	}
+
+	// 只在收到响应时更新重定向信息，网络错误不清除上一次的记录
+	if result.StatusCode > 0 {
+		wasPermanent := link.Redirect.Permanent
+		link.Redirect = models.LinkRedirect{
+			FinalURL:  result.FinalURL,
+			Chain:     result.Redirects,
+			Permanent: models.IsPermanentChain(result.Redirects) && result.FinalURL != link.DefaultURL(),
+			CheckedAt: &now,
+		}
+		columns = append(columns, "redirect_final_url", "redirect_chain", "redirect_permanent", "redirect_checked_at")
+
+		if link.Redirect.Permanent && !wasPermanent {
+			lc.logger.Info("Link permanently redirects",
+				zap.Uint("link_id", link.ID),
+				zap.String("url", link.URL),
+				zap.String("final_url", link.Redirect.FinalURL))
+		}
+	}
+
+	if err := lc.db.Model(link).Select(columns).Updates(link).Error; err != nil {
+		lc.logger.Error("Failed to update link status",
+			zap.Uint("link_id", link.ID),
+			zap.String("url", link.URL),
+			zap.Error(err))
+		return false
+	}
+
+	if changed {
+		lc.logger.Info("Link status changed",
+			zap.Uint("link_id", link.ID),
+			zap.String("url", link.URL),
+			zap.String("from", previous),
+			zap.String("to", link.Status),
+			zap.Int("consecutive_failures", link.ConsecutiveFailures),
+			zap.Int("consecutive_successes", link.ConsecutiveSuccesses))
+	}
+	return changed
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"kk-nav/internal/httpclient"
+	"kk-nav/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxMessageBytes 单条通知的长度上限（钉钉 markdown 上限约 20KB，企业微信 4KB）
+	maxMessageBytes = 4000
+	// notifyTimeout 单个渠道发送超时
+	notifyTimeout = 15 * time.Second
+)
+
+// 状态变更事件类型
+const (
+	EventDown     = "down"
+	EventRecovery = "recovery"
+)
+
+// DefaultNotificationTemplate 默认通知模板
+const DefaultNotificationTemplate = `{{if .Down}}### 🔴 {{len .Down}} 个链接不可用
+{{range .Down}}- {{.Title}} ({{.Category}}) {{.URL}} {{.Reason}}
+{{end}}{{end}}{{if .Recovered}}### 🟢 {{len .Recovered}} 个链接已恢复
+{{range .Recovered}}- {{.Title}} ({{.Category}}) {{.URL}}
+{{end}}{{end}}
+{{.SiteName}} · {{.Time.Format "2006-01-02 15:04:05"}}`
+
+// StatusEvent 链接状态变更事件
+type StatusEvent struct {
+	Type       string // down | recovery
+	LinkID     uint
+	Title      string
+	URL        string
+	CategoryID uint
+	Category   string
+	From       string
+	To         string
+	StatusCode int
+	ErrorClass string
+	Error      string
+	CheckedAt  time.Time
+
+	tagIDs []uint
+}
+
+// Reason 失败原因描述
+func (e StatusEvent) Reason() string {
+	switch {
+	case e.StatusCode > 0 && e.ErrorClass != "":
+		return fmt.Sprintf("[%s %d]", e.ErrorClass, e.StatusCode)
+	case e.ErrorClass != "":
+		return fmt.Sprintf("[%s]", e.ErrorClass)
+	default:
+		return ""
+	}
+}
+
+// statusEventOf 根据状态变化生成通知事件，不需要通知时返回 false。
+// 只有进入 error（达到失败阈值）和从 error 恢复时通知，degraded 不通知。
+func statusEventOf(link *models.Link, from string, result ProbeResult) (StatusEvent, bool) {
+	event := StatusEvent{
+		LinkID:     link.ID,
+		Title:      link.Title,
+		URL:        link.URL,
+		CategoryID: link.CategoryID,
+		From:       from,
+		To:         link.Status,
+		StatusCode: result.StatusCode,
+		ErrorClass: result.ErrorClass,
+		Error:      result.ErrorMessage(),
+		CheckedAt:  result.CheckedAt,
+	}
+	switch {
+	case link.Status == models.LinkStatusError && from != models.LinkStatusError:
+		event.Type = EventDown
+	case link.Status == models.LinkStatusActive && from == models.LinkStatusError:
+		event.Type = EventRecovery
+	default:
+		return event, false
+	}
+	return event, true
+}
+
+// eventBuffer 收集一次检测中的状态变更事件（并发安全）
+type eventBuffer struct {
+	mu     sync.Mutex
+	events []StatusEvent
+}
+
+func (b *eventBuffer) add(link *models.Link, from string, result ProbeResult) {
+	event, ok := statusEventOf(link, from, result)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	b.mu.Unlock()
+}
+
+// notificationData 模板数据
+type notificationData struct {
+	SiteName  string
+	Time      time.Time
+	Events    []StatusEvent
+	Down      []StatusEvent
+	Recovered []StatusEvent
+}
+
+// Notifier 链接状态变更通知服务
+type Notifier struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	client *http.Client
+}
+
+// NewNotifier 创建通知服务
+func NewNotifier(db *gorm.DB, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		db:     db,
+		logger: logger,
+		client: httpclient.New(httpclient.Options{Timeout: notifyTimeout}),
+	}
+}
+
+// Notify 把一次检测中的状态变更按渠道汇总后发送（每个渠道一条消息）
+func (n *Notifier) Notify(ctx context.Context, events []StatusEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	var channels []models.NotificationChannel
+	if err := n.db.Where("enabled = ?", true).Find(&channels).Error; err != nil {
+		n.logger.Error("Failed to load notification channels", zap.Error(err))
+		return
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	n.enrich(events)
+
+	for i := range channels {
+		channel := &channels[i]
+		var routed []StatusEvent
+		for _, event := range events {
+			if event.Type == EventDown && !channel.NotifyDown ||
+				event.Type == EventRecovery && !channel.NotifyRecovery {
+				continue
+			}
+			if channel.Matches(event.CategoryID, event.tagIDs) {
+				routed = append(routed, event)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+
+		err := n.deliver(ctx, channel, routed)
+		n.logger.Info("Notification sent",
+			zap.String("channel", channel.Name),
+			zap.String("type", channel.Type),
+			zap.Int("events", len(routed)),
+			zap.Error(err))
+	}
+}
+
+// SendTest 发送测试通知
+func (n *Notifier) SendTest(ctx context.Context, channel *models.NotificationChannel) error {
+	now := time.Now()
+	events := []StatusEvent{
+		{Type: EventDown, Title: "示例链接", URL: "https://example.com", Category: "示例分类",
+			From: models.LinkStatusActive, To: models.LinkStatusError, ErrorClass: models.ErrorClassTimeout, CheckedAt: now},
+		{Type: EventRecovery, Title: "示例链接 2", URL: "https://example.org", Category: "示例分类",
+			From: models.LinkStatusError, To: models.LinkStatusActive, StatusCode: 200, CheckedAt: now},
+	}
+	return n.deliver(ctx, channel, events)
+}
+
+// deliver 渲染模板并发送，记录最后发送时间和错误
+func (n *Notifier) deliver(ctx context.Context, channel *models.NotificationChannel, events []StatusEvent) error {
+	subject, text, err := render(channel, events)
+	if err == nil {
+		sendCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err = n.send(sendCtx, channel, subject, text)
+		cancel()
+	}
+
+	updates := map[string]interface{}{"last_error": ""}
+	if err != nil {
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_sent_at"] = time.Now()
+	}
+	if channel.ID != 0 {
+		n.db.Model(&models.NotificationChannel{}).Where("id = ?", channel.ID).Updates(updates)
+	}
+	return err
+}
+
+// enrich 补充分类名称和标签ID（用于模板和路由）
+func (n *Notifier) enrich(events []StatusEvent) {
+	linkIDs := make([]uint, 0, len(events))
+	categoryIDs := make([]uint, 0, len(events))
+	for _, event := range events {
+		linkIDs = append(linkIDs, event.LinkID)
+		categoryIDs = append(categoryIDs, event.CategoryID)
+	}
+
+	var categories []models.Category
+	n.db.Select("id, name").Where("id IN ?", categoryIDs).Find(&categories)
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	var linkTags []struct {
+		LinkID uint
+		TagID  uint
+	}
+	n.db.Table("link_tags").Select("link_id, tag_id").Where("link_id IN ?", linkIDs).Scan(&linkTags)
+	tags := make(map[uint][]uint)
+	for _, lt := range linkTags {
+		tags[lt.LinkID] = append(tags[lt.LinkID], lt.TagID)
+	}
+
+	for i := range events {
+		events[i].Category = names[events[i].CategoryID]
+		events[i].tagIDs = tags[events[i].LinkID]
+	}
+}
+
+// render 渲染通知标题和正文
+func render(channel *models.NotificationChannel, events []StatusEvent) (string, string, error) {
+	data := notificationData{
+		SiteName: models.GetSetting("site_name"),
+		Time:     time.Now(),
+		Events:   events,
+	}
+	for _, event := range events {
+		if event.Type == EventDown {
+			data.Down = append(data.Down, event)
+		} else {
+			data.Recovered = append(data.Recovered, event)
+		}
+	}
+
+	source := channel.Template
+	if source == "" {
+		source = DefaultNotificationTemplate
+	}
+	tmpl, err := template.New(channel.Name).Parse(source)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("render template: %w", err)
+	}
+
+	subject := fmt.Sprintf("[%s] 链接状态变更：%d 个不可用，%d 个已恢复", data.SiteName, len(data.Down), len(data.Recovered))
+	return subject, truncate(buf.String(), maxMessageBytes), nil
+}
+
+// truncate 按字节截断文本，不截断多字节字符
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const suffix = "\n…"
+	cut := max - len(suffix)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + suffix
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"kk-nav/internal/models"
+)
+
+// send 按渠道类型发送消息
+func (n *Notifier) send(ctx context.Context, channel *models.NotificationChannel, subject, text string) error {
+	switch channel.Type {
+	case models.ChannelDingTalk:
+		return n.sendDingTalk(ctx, channel.Config, subject, text)
+	case models.ChannelFeishu:
+		return n.sendFeishu(ctx, channel.Config, text)
+	case models.ChannelWeCom:
+		return n.sendWeCom(ctx, channel.Config, text)
+	case models.ChannelSlack:
+		return n.sendSlack(ctx, channel.Config, text)
+	case models.ChannelEmail:
+		return sendEmail(ctx, channel.Config, subject, text)
+	default:
+		return fmt.Errorf("unsupported channel type: %s", channel.Type)
+	}
+}
+
+// sendDingTalk 钉钉机器人，配置了密钥时在 URL 上加签
+func (n *Notifier) sendDingTalk(ctx context.Context, cfg models.NotificationConfig, subject, text string) error {
+	webhook := cfg.WebhookURL
+	if cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(cfg.Secret))
+		mac.Write([]byte(timestamp + "\n" + cfg.Secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+		u, err := url.Parse(webhook)
+		if err != nil {
+			return err
+		}
+		query := u.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", sign)
+		u.RawQuery = query.Encode()
+		webhook = u.String()
+	}
+
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	err := n.postJSON(ctx, webhook, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": subject,
+			"text":  text,
+		},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("dingtalk error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// sendFeishu 飞书机器人，配置了密钥时在请求体中加签
+func (n *Notifier) sendFeishu(ctx context.Context, cfg models.NotificationConfig, text string) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content": map[string]string{
+			"text": text,
+		},
+	}
+	if cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+cfg.Secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := n.postJSON(ctx, cfg.WebhookURL, payload, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("feishu error %d: %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// sendWeCom 企业微信群机器人
+func (n *Notifier) sendWeCom(ctx context.Context, cfg models.NotificationConfig, text string) error {
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	err := n.postJSON(ctx, cfg.WebhookURL, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": text,
+		},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("wecom error %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// sendSlack Slack 兼容的 Incoming Webhook（Mattermost、Rocket.Chat 等）
+func (n *Notifier) sendSlack(ctx context.Context, cfg models.NotificationConfig, text string) error {
+	return n.postJSON(ctx, cfg.WebhookURL, map[string]string{"text": text}, nil)
+}
+
+// postJSON 发送 JSON 请求，out 不为空时解析 JSON 响应
+func (n *Notifier) postJSON(ctx context.Context, webhook string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("invalid webhook response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sendEmail 通过 SMTP 发送纯文本邮件
+func sendEmail(ctx context.Context, cfg models.NotificationConfig, subject, text string) error {
+	// 信封（MAIL FROM / RCPT TO）只能使用纯地址，显示名称只出现在邮件头中
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	to := make([]*mail.Address, 0, len(cfg.To))
+	toHeader := make([]string, 0, len(cfg.To))
+	for _, s := range cfg.To {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return fmt.Errorf("invalid recipient address: %w", err)
+		}
+		to = append(to, addr)
+		toHeader = append(toHeader, addr.String())
+	}
+
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(port))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if port == 465 {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.SMTPHost}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(toHeader, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"kk-nav/internal/config"
+	"kk-nav/internal/httpclient"
+	"kk-nav/internal/models"
+)
+
+const (
+	// maxDrainBytes 读取响应体的上限，读完后连接才能被复用
+	maxDrainBytes = 64 << 10
+	// maxBodyBytes 关键字匹配时读取响应体的上限
+	maxBodyBytes = 1 << 20
+	// maxRedirects 最多跟随的重定向次数
+	maxRedirects = 10
+)
+
+// errTooManyRedirects 重定向次数超过 maxRedirects
+var errTooManyRedirects = errors.New("too many redirects")
+
+// ProbeResult 单次探测结果
+type ProbeResult struct {
+	URL        string
+	Status     string // active | error
+	StatusCode int
+	Latency    time.Duration
+	ErrorClass string // 失败原因分类（models.ErrorClass*），成功时为空
+	Err        error
+	CheckedAt  time.Time
+
+	// Certificate HTTPS 链接的服务器证书，握手失败或非 HTTPS 时为 nil
+	Certificate *CertificateInfo
+
+	// Redirects 重定向链（不含最终地址），FinalURL 为重定向后的地址，没有重定向时都为空
+	Redirects []models.RedirectHop
+	FinalURL  string
+}
+
+// CertificateInfo 服务器证书信息
+type CertificateInfo struct {
+	NotAfter      time.Time `json:"not_after"`
+	Issuer        string    `json:"issuer"`
+	Subject       string    `json:"subject"`
+	DNSNames      []string  `json:"dns_names"`
+	HostnameMatch bool      `json:"hostname_match"`
+}
+
+// ErrorMessage 返回错误描述，成功时为空
+func (r ProbeResult) ErrorMessage() string {
+	if r.Err == nil {
+		return ""
+	}
+	return r.Err.Error()
+}
+
+// Prober 链接探测器，所有检测共享 Transport 以复用连接
+type Prober struct {
+	client           *http.Client
+	noRedirectClient *http.Client
+
+	// 跳过证书校验的链接使用单独的 Transport
+	insecureClient           *http.Client
+	insecureNoRedirectClient *http.Client
+}
+
+// NewProber 创建链接探测器
+func NewProber(cfg config.CheckerConfig) *Prober {
+	perHost := cfg.PerHostConcurrency
+	if perHost <= 0 {
+		perHost = 2
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	opts := httpclient.Options{
+		Timeout:             timeout,
+		MaxIdleConnsPerHost: perHost,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errTooManyRedirects
+			}
+			return nil
+		},
+	}
+	noRedirect := opts
+	noRedirect.NoRedirect = true
+
+	transport := httpclient.NewTransport(opts)
+	insecureOpts := opts
+	insecureOpts.InsecureSkipVerify = true
+	insecureTransport := httpclient.NewTransport(insecureOpts)
+
+	return &Prober{
+		client:                   httpclient.NewWithTransport(transport, opts),
+		noRedirectClient:         httpclient.NewWithTransport(transport, noRedirect),
+		insecureClient:           httpclient.NewWithTransport(insecureTransport, opts),
+		insecureNoRedirectClient: httpclient.NewWithTransport(insecureTransport, noRedirect),
+	}
+}
+
+// clientFor 按探测配置选择客户端
+func (p *Prober) clientFor(probe models.LinkProbe) *http.Client {
+	switch {
+	case probe.SkipTLSVerify && !probe.ShouldFollowRedirects():
+		return p.insecureNoRedirectClient
+	case probe.SkipTLSVerify:
+		return p.insecureClient
+	case !probe.ShouldFollowRedirects():
+		return p.noRedirectClient
+	default:
+		return p.client
+	}
+}
+
+// Probe 按链接的探测配置探测单个URL
+func (p *Prober) Probe(ctx context.Context, url string, probe models.LinkProbe) ProbeResult {
+	result := ProbeResult{
+		URL:       url,
+		Status:    "error",
+		CheckedAt: time.Now(),
+	}
+
+	matcher, err := probe.KeywordMatcher()
+	if err != nil {
+		result.ErrorClass = models.ErrorClassInvalidConfig
+		result.Err = err
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, probe.HTTPMethod(), url, nil)
+	if err != nil {
+		result.ErrorClass = models.ErrorClassInvalidURL
+		result.Err = err
+		return result
+	}
+	for name, value := range probe.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := p.clientFor(probe).Do(req)
+	if err != nil {
+		result.Latency = time.Since(start)
+		result.ErrorClass = classifyError(err)
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.Certificate = certificateOf(resp, req.URL.Hostname())
+	result.Redirects, result.FinalURL = redirectsOf(resp)
+
+	var body []byte
+	if matcher != nil {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	} else {
+		_, err = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	}
+	result.Latency = time.Since(start)
+	result.StatusCode = resp.StatusCode
+
+	if !probe.AcceptsStatus(resp.StatusCode) {
+		result.ErrorClass = classifyStatus(resp.StatusCode)
+		result.Err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		return result
+	}
+
+	if matcher != nil {
+		if err != nil {
+			result.ErrorClass = classifyError(err)
+			result.Err = err
+			return result
+		}
+		if matcher(body) == probe.KeywordAbsent {
+			result.ErrorClass = models.ErrorClassKeywordMismatch
+			if probe.KeywordAbsent {
+				result.Err = fmt.Errorf("response body matches forbidden keyword %q", probe.Keyword)
+			} else {
+				result.Err = fmt.Errorf("response body does not match keyword %q", probe.Keyword)
+			}
+			return result
+		}
+	}
+
+	result.Status = "active"
+	return result
+}
+
+// certificateOf 提取链接主机的证书（跟随重定向时取第一跳的连接）
+func certificateOf(resp *http.Response, host string) *CertificateInfo {
+	first := resp
+	for first.Request != nil && first.Request.Response != nil {
+		first = first.Request.Response
+	}
+	if first.TLS == nil || len(first.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	cert := first.TLS.PeerCertificates[0]
+	return &CertificateInfo{
+		NotAfter:      cert.NotAfter,
+		Issuer:        cert.Issuer.String(),
+		Subject:       cert.Subject.String(),
+		DNSNames:      cert.DNSNames,
+		HostnameMatch: cert.VerifyHostname(host) == nil,
+	}
+}
+
+// redirectsOf 提取重定向链和最终地址。
+// 不跟随重定向时，3xx 响应记为一跳，Location 作为最终地址。
+func redirectsOf(resp *http.Response) ([]models.RedirectHop, string) {
+	var hops []models.RedirectHop
+	for r := resp; r.Request != nil && r.Request.Response != nil; r = r.Request.Response {
+		prev := r.Request.Response
+		hops = append([]models.RedirectHop{{URL: prev.Request.URL.String(), StatusCode: prev.StatusCode}}, hops...)
+	}
+	if len(hops) > 0 {
+		return hops, resp.Request.URL.String()
+	}
+
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if location, err := resp.Location(); err == nil {
+			return []models.RedirectHop{{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode}}, location.String()
+		}
+	}
+	return nil, ""
+}
+
+// classifyError 将请求错误归类
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, errTooManyRedirects):
+		return models.ErrorClassTooManyRedirects
+	case errors.Is(err, context.DeadlineExceeded), os.IsTimeout(err):
+		return models.ErrorClassTimeout
+	case errors.As(err, &dnsErr):
+		return models.ErrorClassDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.ErrorClassConnRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return models.ErrorClassConnReset
+	case errors.As(err, &invalidCert) && invalidCert.Reason == x509.Expired:
+		return models.ErrorClassTLSExpired
+	case errors.As(err, &hostnameErr):
+		return models.ErrorClassTLSHostname
+	case errors.As(err, &unknownAuthority):
+		return models.ErrorClassTLSUntrusted
+	case errors.As(err, &certErr), errors.As(err, &invalidCert), errors.As(err, &recordErr):
+		return models.ErrorClassTLS
+	default:
+		return models.ErrorClassNetwork
+	}
+}
+
+// classifyStatus 将非预期的 HTTP 状态码归类
+func classifyStatus(code int) string {
+	switch {
+	case code >= 500:
+		return models.ErrorClassHTTP5xx
+	case code >= 400:
+		return models.ErrorClassHTTP4xx
+	default:
+		return models.ErrorClassHTTPStatus
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025 kk
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxWindow 统计时间窗口的上限
+const MaxWindow = 90 * 24 * time.Hour
+
+// ParseWindow 解析统计时间窗口，支持 Go duration（如 12h）和天数（如 7d）
+func ParseWindow(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var window time.Duration
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window: %s", s)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window: %s", s)
+		}
+		window = d
+	}
+
+	if window < time.Second || window > MaxWindow {
+		return 0, fmt.Errorf("window must be between 1s and %dd", int(MaxWindow.Hours()/24))
+	}
+	return window, nil
+}
+
+// Percent 计算百分比，保留两位小数
+func Percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
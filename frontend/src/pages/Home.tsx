@@ -17,7 +17,8 @@ export default function Home() {
   const [links, setLinks] = useState<Link[]>([])
   const [tags, setTags] = useState<Tag[]>([])
   const [stats, setStats] = useState<Stats | null>(null)
-  const [searchQuery, setSearchQuery] = useState('')
+  // 浏览器搜索没有直接命中时跳转到 /?search=...
+  const [searchQuery, setSearchQuery] = useState(() => new URLSearchParams(window.location.search).get('search') ?? '')
   const [selectedTag, setSelectedTag] = useState<string | null>(null)
   const [favorites, setFavorites] = useState<number[]>([])
   const [loading, setLoading] = useState(true)
@@ -127,8 +128,9 @@ export default function Home() {
 
   const handleClick = async (linkId: number, url: string) => {
     try {
-      await api.post(`/links/${linkId}/click`)
-      window.open(url, '_blank')
+      // 后端返回实际跳转地址（环境地址、模板变量默认值）
+      const response = await api.post(`/links/${linkId}/click`) as any
+      window.open(response.code === 0 && response.data?.url ? response.data.url : url, '_blank')
     } catch (error) {
       console.error('Failed to record click:', error)
       window.open(url, '_blank')
@@ -300,11 +302,19 @@ export default function Home() {
                                   className={`w-2 h-2 rounded-full ${
                                     link.status === 'active'
                                       ? 'bg-green-500'
+                                      : link.status === 'degraded'
+                                      ? 'bg-yellow-500'
                                       : link.status === 'error'
                                       ? 'bg-red-500'
                                       : 'bg-gray-500'
                                   }`}
                                 />
+                                <img
+                                  src={`/api/v1/links/${link.id}/icon?v=${link.icon_hash || ''}`}
+                                  alt=""
+                                  loading="lazy"
+                                  className="w-5 h-5 rounded"
+                                />
                                 {link.title}
                               </CardTitle>
                               {isAuthenticated && (
@@ -323,6 +333,11 @@ export default function Home() {
                                 </Button>
                               )}
                             </div>
+                            {link.maintenance && (
+                              <div className="text-xs text-blue-600 bg-blue-50 rounded px-2 py-1">
+                                维护中{link.maintenance_message ? `：${link.maintenance_message}` : ''}
+                              </div>
+                            )}
                             {link.description && (
                               <CardDescription className="line-clamp-2">
                                 {link.description}
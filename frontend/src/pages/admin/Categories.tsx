@@ -32,6 +32,7 @@ export default function Categories() {
     color: '#007bff',
     sort_order: 0,
     active: true,
+    hidden_on_status_page: false,
   })
 
   useEffect(() => {
@@ -62,6 +63,7 @@ export default function Categories() {
         color: category.color || '#007bff',
         sort_order: category.sort_order,
         active: category.active,
+        hidden_on_status_page: category.hidden_on_status_page ?? false,
       })
     } else {
       setEditingCategory(null)
@@ -72,6 +74,7 @@ export default function Categories() {
         color: '#007bff',
         sort_order: categories.length + 1,
         active: true,
+        hidden_on_status_page: false,
       })
     }
     setDialogOpen(true)
@@ -304,6 +307,15 @@ export default function Categories() {
               />
               <Label htmlFor="active">启用</Label>
             </div>
+            <div className="flex items-center gap-2">
+              <input
+                type="checkbox"
+                id="hidden_on_status_page"
+                checked={formData.hidden_on_status_page}
+                onChange={(e) => setFormData({ ...formData, hidden_on_status_page: e.target.checked })}
+              />
+              <Label htmlFor="hidden_on_status_page">不在状态页展示</Label>
+            </div>
           </div>
           <DialogFooter>
             <Button variant="outline" onClick={() => setDialogOpen(false)}>
@@ -33,7 +33,7 @@ export default function Links() {
     description: '',
     category_id: 0,
     sort_order: 0,
-    status: 'active' as 'active' | 'inactive' | 'error',
+    status: 'active' as Link['status'],
     tag_names: [] as string[],
   })
 
@@ -182,9 +182,12 @@ export default function Links() {
                     <td className="p-4">{link.category?.name || '-'}</td>
                     <td className="p-4">
                       <span
+                        title={link.last_error_class ? `${link.last_error_class}: ${link.last_error}` : undefined}
                         className={`px-2 py-1 rounded text-xs ${
                           link.status === 'active'
                             ? 'bg-green-100 text-green-800'
+                            : link.status === 'degraded'
+                            ? 'bg-yellow-100 text-yellow-800'
                             : link.status === 'error'
                             ? 'bg-red-100 text-red-800'
                             : 'bg-gray-100 text-gray-800'
@@ -192,6 +195,8 @@ export default function Links() {
                       >
                         {link.status === 'active'
                           ? '正常'
+                          : link.status === 'degraded'
+                          ? '不稳定'
                           : link.status === 'error'
                           ? '错误'
                           : '禁用'}
@@ -301,11 +306,12 @@ export default function Links() {
                   onChange={(e) =>
                     setFormData({
                       ...formData,
-                      status: e.target.value as 'active' | 'inactive' | 'error',
+                      status: e.target.value as Link['status'],
                     })
                   }
                 >
                   <option value="active">正常</option>
+                  <option value="degraded">不稳定</option>
                   <option value="inactive">禁用</option>
                   <option value="error">错误</option>
                 </select>
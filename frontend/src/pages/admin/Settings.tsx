@@ -149,6 +149,17 @@ export default function Settings() {
             />
             <Label htmlFor="enable_analytics">启用统计分析</Label>
           </div>
+          <div className="flex items-center gap-2">
+            <input
+              type="checkbox"
+              id="enable_favicon_fetch"
+              checked={settings.enable_favicon_fetch === 'true'}
+              onChange={(e) =>
+                handleChange('enable_favicon_fetch', e.target.checked ? 'true' : 'false')
+              }
+            />
+            <Label htmlFor="enable_favicon_fetch">自动获取链接图标</Label>
+          </div>
           <div>
             <Label htmlFor="check_interval_hours">链接检测间隔（小时）</Label>
             <Input
@@ -8,6 +8,7 @@ export interface User {
   email: string
   username: string
   role: 'user' | 'admin'
+  preferred_environment?: string
   created_at: string
   updated_at: string
 }
@@ -20,6 +21,7 @@ export interface Category {
   color?: string
   sort_order: number
   active: boolean
+  hidden_on_status_page?: boolean
   created_at: string
   updated_at: string
 }
@@ -36,17 +38,59 @@ export interface Link {
   id: number
   title: string
   url: string
+  url_variables?: URLVariable[] // 不为空时 url 是模板，{name} 在点击时替换
+  slug?: string | null // 短链接名，/go/<slug> 跳转
+  aliases?: LinkAlias[]
   description?: string
   icon?: string
-  status: 'active' | 'inactive' | 'error'
+  icon_hash?: string // 图标内容的哈希，为空时 /links/:id/icon 返回字母图标
+  status: 'active' | 'degraded' | 'inactive' | 'error'
   click_count: number
+  last_checked_at?: string
+  last_status_code?: number
+  last_error_class?: string
+  last_error?: string
+  zone?: string
+  maintenance?: boolean
+  maintenance_message?: string
+  highlight?: Record<string, string> // 搜索命中片段（title / description / url / tags / category）
   category_id: number
   category?: Category
   tags?: Tag[]
+  endpoints?: LinkEndpoint[]
   created_at: string
   updated_at: string
 }
 
+export interface LinkAlias {
+  id: number
+  link_id: number
+  slug: string
+  created_at: string
+}
+
+export interface URLVariable {
+  name: string
+  description?: string
+  default: string
+  values?: string[]
+}
+
+export interface LinkEndpoint {
+  id: number
+  link_id: number
+  environment: string
+  url: string
+  is_default: boolean
+  sort_order: number
+  status: '' | 'active' | 'error'
+  last_checked_at?: string
+  last_status_code?: number
+  last_latency_ms?: number
+  last_error_class?: string
+  last_error?: string
+}
+
 export interface Stats {
   total_links: number
   total_categories: number